@@ -0,0 +1,351 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func benchRequest(b *testing.B, router http.Handler, r *http.Request) {
+	w := new(mockResponseWriter)
+	u := r.URL
+	rq := u.RawQuery
+	r.RequestURI = u.RequestURI()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		u.RawQuery = rq
+		router.ServeHTTP(w, r)
+	}
+}
+
+func benchRoutes(b *testing.B, router http.Handler, requests []request) {
+	w := new(mockResponseWriter)
+	r, _ := http.NewRequest("GET", "/", nil)
+	u := r.URL
+	rq := u.RawQuery
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			r.Method = req.method
+			r.RequestURI = req.path
+			u.Path = req.path
+			u.RawQuery = rq
+			router.ServeHTTP(w, r)
+		}
+	}
+}
+
+// benchRequestParallel is benchRequest run by GOMAXPROCS goroutines, each
+// with a response writer and request of its own.
+func benchRequestParallel(b *testing.B, router http.Handler, method, path string) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		w := new(mockResponseWriter)
+		r, _ := http.NewRequest(method, path, nil)
+		u := r.URL
+		rq := u.RawQuery
+		r.RequestURI = u.RequestURI()
+
+		for pb.Next() {
+			u.RawQuery = rq
+			router.ServeHTTP(w, r)
+		}
+	})
+}
+
+// benchRoutesParallel is benchRoutes run by GOMAXPROCS goroutines, each with
+// a response writer and request of its own.
+func benchRoutesParallel(b *testing.B, router http.Handler, requests []request) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		w := new(mockResponseWriter)
+		r, _ := http.NewRequest("GET", "/", nil)
+		u := r.URL
+		rq := u.RawQuery
+
+		for pb.Next() {
+			for _, req := range requests {
+				r.Method = req.method
+				r.RequestURI = req.path
+				u.Path = req.path
+				u.RawQuery = rq
+				router.ServeHTTP(w, r)
+			}
+		}
+	})
+}
+
+// benchEndToEnd serves router on a loopback HTTP server and sends each of
+// requests per op through an http.Client, reusing its connection.
+func benchEndToEnd(b *testing.B, router http.Handler, requests []request) {
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	transport := new(http.Transport)
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	reqs := make([]*http.Request, len(requests))
+	for i, req := range requests {
+		reqs[i], _ = http.NewRequest(req.method, srv.URL+req.path, nil)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, req := range reqs {
+			resp, err := client.Do(req)
+			if err != nil {
+				b.Fatal(err)
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	b.StopTimer()
+}
+
+// mode is the way a benchmark sends its requests.
+type mode int
+
+const (
+	serial   mode = iota // by a single goroutine
+	parallel             // by GOMAXPROCS goroutines
+	endToEnd             // over HTTP by a single client
+)
+
+var modeNames = []string{"serial", "parallel", "e2e"}
+
+func (m mode) String() string {
+	return modeNames[m]
+}
+
+// parseMode returns the mode named name.
+func parseMode(name string) (mode, error) {
+	for m, n := range modeNames {
+		if n == name {
+			return mode(m), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q, want one of %s", name, strings.Join(modeNames, ", "))
+}
+
+// loadedRouter is the routing structure of a router for a route set.
+type loadedRouter struct {
+	http.Handler
+
+	router Router
+
+	// routes and requests are the ones of the route set the router is able
+	// to load, excluded are the others.
+	routes   []route
+	requests []request
+	excluded []route
+
+	// mem is the memory footprint of the routing structure, it is not
+	// measured for micro suites.
+	mem memStats
+
+	verified  bool
+	verifyErr error
+}
+
+// verify runs the routing verification once and returns its result. It is
+// not part of loading, since serving requests makes some routers allocate
+// global state, which would show up in the memory of the next one loaded.
+func (lr *loadedRouter) verify() error {
+	if !lr.verified {
+		lr.verifyErr = verify(lr.router, lr.routes, lr.requests)
+		lr.verified = true
+	}
+	return lr.verifyErr
+}
+
+// coverage returns the percentage of the route set lr was loaded with.
+func (lr *loadedRouter) coverage() float64 {
+	return 100 * float64(len(lr.routes)) / float64(len(lr.routes)+len(lr.excluded))
+}
+
+// suite is a route set and the benchmarks run on it.
+type suite struct {
+	name   string
+	routes []route
+
+	// handler is registered for every route, nil registers the no-op
+	// handler of each router.
+	handler Handler
+
+	// micro is set for the micro benchmarks, whose single route is not
+	// worth reporting the memory of.
+	micro bool
+
+	benchmarks []benchmark
+
+	requests []request
+	loaded   map[string]*loadedRouter
+}
+
+// benchmark is a benchmark of a suite. Each op requests path with GET, or
+// every route of the suite once if path is empty.
+type benchmark struct {
+	name string
+	path string
+}
+
+// suites is the registry of all suites in registration order.
+var suites []*suite
+
+// registerSuite generates the requests of s, unless it has them, and adds it
+// to the registry.
+func registerSuite(s *suite) *suite {
+	if s.requests == nil {
+		s.requests = genRequests(s.routes, requestSeed)
+	}
+	suites = append(suites, s)
+	return s
+}
+
+// eachRouter calls fn for each router supporting the routes of s, in
+// registration order.
+func (s *suite) eachRouter(fn func(r Router)) {
+	for _, r := range routers {
+		if supports(r, s.routes) {
+			fn(r)
+		}
+	}
+}
+
+// router returns the routing structure of r for s, loading it on first use.
+// Loading measures the memory footprint of the structure and prints it,
+// preceded by the suite header if it is the first router loaded. Routes
+// needing capabilities r lacks are left out, which is printed as its
+// coverage of the route set.
+func (s *suite) router(r Router) *loadedRouter {
+	if lr, ok := s.loaded[r.Name()]; ok {
+		return lr
+	}
+	if s.loaded == nil {
+		s.loaded = make(map[string]*loadedRouter)
+		if !s.micro {
+			fmt.Fprintf(os.Stderr, "#%s Routes: %d\n", s.name, len(s.routes))
+		}
+	}
+
+	lr := &loadedRouter{router: r}
+	indexes, excluded := filterRoutes(r, s.routes)
+	for _, i := range indexes {
+		lr.routes = append(lr.routes, s.routes[i])
+		lr.requests = append(lr.requests, s.requests[i])
+	}
+	needs := routeNeeds(s.routes)
+	var missing Capability
+	inexpressible := 0
+	for _, i := range excluded {
+		lr.excluded = append(lr.excluded, s.routes[i])
+		if m := needs[i] &^ r.Capabilities(); m != 0 {
+			missing |= m
+		} else {
+			inexpressible++
+		}
+	}
+
+	if s.micro {
+		lr.Handler = loadMicro(r, lr.routes, s.handler)
+	} else {
+		lr.mem = measureMem(len(lr.routes), func() {
+			lr.Handler = load(r, lr.routes, s.handler)
+		})
+		fmt.Fprintf(os.Stderr, "   %s: %v\n", r.Name(), lr.mem)
+	}
+	if n := len(excluded) - inexpressible; n > 0 {
+		fmt.Fprintf(os.Stderr, "     coverage %.1f%%, excluded %d routes needing %s\n",
+			lr.coverage(), n, missing)
+	}
+	if inexpressible > 0 {
+		fmt.Fprintf(os.Stderr, "     coverage %.1f%%, excluded %d routes the dialect can not express\n",
+			lr.coverage(), inexpressible)
+	}
+	s.loaded[r.Name()] = lr
+	return lr
+}
+
+// run runs the named benchmark of s serially.
+func (s *suite) run(b *testing.B, name string) {
+	for _, bm := range s.benchmarks {
+		if bm.name == name {
+			s.benchRouters(b, bm, serial)
+			return
+		}
+	}
+	b.Fatalf("suite %s has no benchmark %s", s.name, name)
+}
+
+// benchRouters runs bm in mode m as a sub-benchmark for each router, and
+// reports the router's coverage of the route set next to the results.
+// Routers are loaded only if their sub-benchmark runs.
+func (s *suite) benchRouters(b *testing.B, bm benchmark, m mode) {
+	s.eachRouter(func(r Router) {
+		b.Run(r.Name(), func(b *testing.B) {
+			benchLoaded(b, s.router(r), bm, m)
+		})
+	})
+}
+
+// benchLoaded benchmarks bm on lr and reports the time per request and the
+// memory footprint of the routing structure next to the results. A router
+// which fails the routing verification is not benchmarked but reported as
+// skipped, together with the routes it got wrong.
+func benchLoaded(b *testing.B, lr *loadedRouter, bm benchmark, m mode) {
+	if err := lr.verify(); err != nil {
+		fmt.Printf("%s\tFAILED routing verification\n", b.Name())
+		b.Skipf("FAILED routing verification: %v", err)
+	}
+	requests := lr.requests
+	if bm.path != "" {
+		requests = []request{{method: "GET", path: bm.path}}
+	}
+	switch {
+	case m == endToEnd:
+		benchEndToEnd(b, lr, requests)
+	case bm.path != "" && m == parallel:
+		benchRequestParallel(b, lr, "GET", bm.path)
+	case bm.path != "":
+		req, _ := http.NewRequest("GET", bm.path, nil)
+		benchRequest(b, lr, req)
+	case m == parallel:
+		benchRoutesParallel(b, lr, requests)
+	default:
+		benchRoutes(b, lr, requests)
+	}
+	// the time of the requests alone, without the reporting below
+	b.StopTimer()
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(requests)), "ns/req")
+	b.ReportMetric(lr.coverage(), "%routes")
+	if m == parallel {
+		// go test names the benchmark after GOMAXPROCS only if -cpu sets it
+		b.ReportMetric(float64(runtime.GOMAXPROCS(0)), "procs")
+	}
+	if lr.mem.routes > 0 {
+		lr.mem.report(b)
+	}
+}
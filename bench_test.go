@@ -5,213 +5,35 @@
 package main
 
 import (
-	"net/http"
-	"runtime"
+	"flag"
+	"os"
 	"testing"
 )
 
-func calcMem(name string, load func()) {
-	m := new(runtime.MemStats)
-
-	// before
-	runtime.GC()
-	runtime.ReadMemStats(m)
-	before := m.HeapAlloc
-
-	load()
-
-	// after
-	runtime.GC()
-	runtime.ReadMemStats(m)
-	after := m.HeapAlloc
-	println("   "+name+":", after-before, "Bytes")
-}
-
-func benchRequest(b *testing.B, router http.Handler, r *http.Request) {
-	w := new(mockResponseWriter)
-	u := r.URL
-	rq := u.RawQuery
-	r.RequestURI = u.RequestURI()
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		u.RawQuery = rq
-		router.ServeHTTP(w, r)
-	}
-}
-
-func benchRoutes(b *testing.B, router http.Handler, routes []route) {
-	w := new(mockResponseWriter)
-	r, _ := http.NewRequest("GET", "/", nil)
-	u := r.URL
-	rq := u.RawQuery
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		for _, route := range routes {
-			r.Method = route.method
-			r.RequestURI = route.path
-			u.Path = route.path
-			u.RawQuery = rq
-			router.ServeHTTP(w, r)
-		}
+// TestMain prints the environment before the benchmarks run, so it is part
+// of saved output. go test prints goos, goarch and cpu itself.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if f := flag.Lookup("test.bench"); f != nil && f.Value.String() != "" {
+		captureEnv().printConfig(os.Stdout, "goos", "goarch", "cpu")
 	}
+	os.Exit(m.Run())
 }
 
 // Micro Benchmarks
 
-// Route with Param (no write)
-func BenchmarkBeego_Param(b *testing.B) {
-	router := loadBeegoSingle("GET", "/user/:name", beegoHandler)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGoji_Param(b *testing.B) {
-	router := loadGojiSingle("GET", "/user/:name", httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGorillaMux_Param(b *testing.B) {
-	router := loadGorillaMuxSingle("GET", "/user/{name}", httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkMartini_Param(b *testing.B) {
-	router := loadMartiniSingle("GET", "/user/:name", martiniHandler)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkMacaron_Param(b *testing.B) {
-	router := loadMacaronSingle("GET", "/user/:name", macaronHandler)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-// Route with 5 Params (no write)
-const fiveColon = "/:a/:b/:c/:d/:e"
-const fiveBrace = "/{a}/{b}/{c}/{d}/{e}"
-const fiveRoute = "/test/test/test/test/test"
-
-func BenchmarkBeego_Param5(b *testing.B) {
-	router := loadBeegoSingle("GET", fiveColon, beegoHandler)
-
-	r, _ := http.NewRequest("GET", fiveRoute, nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGoji_Param5(b *testing.B) {
-	router := loadGojiSingle("GET", fiveColon, httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", fiveRoute, nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGorillaMux_Param5(b *testing.B) {
-	router := loadGorillaMuxSingle("GET", fiveBrace, httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", fiveRoute, nil)
-	benchRequest(b, router, r)
+func BenchmarkParam(b *testing.B) {
+	paramSuite.run(b, "Param")
 }
 
-func BenchmarkMartini_Param5(b *testing.B) {
-	router := loadMartiniSingle("GET", fiveColon, martiniHandler)
-
-	r, _ := http.NewRequest("GET", fiveRoute, nil)
-	benchRequest(b, router, r)
+func BenchmarkParam5(b *testing.B) {
+	param5Suite.run(b, "Param5")
 }
 
-func BenchmarkMacaron_Param5(b *testing.B) {
-	router := loadMacaronSingle("GET", fiveColon, macaronHandler)
-
-	r, _ := http.NewRequest("GET", fiveRoute, nil)
-	benchRequest(b, router, r)
-}
-
-// Route with 20 Params (no write)
-const twentyColon = "/:a/:b/:c/:d/:e/:f/:g/:h/:i/:j/:k/:l/:m/:n/:o/:p/:q/:r/:s/:t"
-const twentyBrace = "/{a}/{b}/{c}/{d}/{e}/{f}/{g}/{h}/{i}/{j}/{k}/{l}/{m}/{n}/{o}/{p}/{q}/{r}/{s}/{t}"
-const twentyRoute = "/a/b/c/d/e/f/g/h/i/j/k/l/m/n/o/p/q/r/s/t"
-
-func BenchmarkBeego_Param20(b *testing.B) {
-	router := loadBeegoSingle("GET", twentyColon, beegoHandler)
-
-	r, _ := http.NewRequest("GET", twentyRoute, nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGoji_Param20(b *testing.B) {
-	router := loadGojiSingle("GET", twentyColon, httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", twentyRoute, nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGorillaMux_Param20(b *testing.B) {
-	router := loadGorillaMuxSingle("GET", twentyBrace, httpHandlerFunc)
-
-	r, _ := http.NewRequest("GET", twentyRoute, nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkMartini_Param20(b *testing.B) {
-	router := loadMartiniSingle("GET", twentyColon, martiniHandler)
-
-	r, _ := http.NewRequest("GET", twentyRoute, nil)
-	benchRequest(b, router, r)
+func BenchmarkParam20(b *testing.B) {
+	param20Suite.run(b, "Param20")
 }
 
-func BenchmarkMacaron_Param20(b *testing.B) {
-	router := loadMacaronSingle("GET", twentyColon, macaronHandler)
-
-	r, _ := http.NewRequest("GET", twentyRoute, nil)
-	benchRequest(b, router, r)
-}
-
-// Route with Param and write
-func BenchmarkBeego_ParamWrite(b *testing.B) {
-	router := loadBeegoSingle("GET", "/user/:name", beegoHandlerWrite)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGoji_ParamWrite(b *testing.B) {
-	router := loadGojiSingle("GET", "/user/:name", gojiFuncWrite)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkGorillaMux_ParamWrite(b *testing.B) {
-	router := loadGorillaMuxSingle("GET", "/user/{name}", gorillaHandlerWrite)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkMartini_ParamWrite(b *testing.B) {
-	router := loadMartiniSingle("GET", "/user/:name", martiniHandlerWrite)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
-}
-
-func BenchmarkMacaron_ParamWrite(b *testing.B) {
-	router := loadMacaronSingle("GET", "/user/:name", macaronHandlerWrite)
-
-	r, _ := http.NewRequest("GET", "/user/gordon", nil)
-	benchRequest(b, router, r)
+func BenchmarkParamWrite(b *testing.B) {
+	paramWriteSuite.run(b, "ParamWrite")
 }
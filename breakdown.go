@@ -0,0 +1,232 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func breakdownCommand(args []string) error {
+	flags := newFlagSet("breakdown", "")
+	var (
+		suiteName  = flags.String("suite", "GithubAPI", "the suite whose routes to time")
+		routerList = flags.String("router", "", "comma-separated routers to time, all if empty")
+		duration   = flags.Duration("duration", 20*time.Millisecond, "time each route for at least `d`")
+		top        = flags.Int("top", 5, "list the `n` slowest routes of each router")
+		sources    = newSuiteSources(flags)
+	)
+	flags.Parse(args)
+	if err := sources.register(); err != nil {
+		return err
+	}
+
+	cfg, err := newRunConfig(*suiteName, *routerList, serial.String(), 1)
+	if err != nil {
+		return err
+	}
+	if len(cfg.suites) != 1 {
+		return fmt.Errorf("breakdown times a single suite")
+	}
+	s := cfg.suites[0]
+
+	var bds []*breakdown
+	for _, r := range cfg.routers {
+		if !supports(r, s.routes) {
+			continue
+		}
+		lr := s.router(r)
+		if err := lr.verify(); err != nil {
+			fmt.Printf("%s\tFAILED routing verification\n", r.Name())
+			continue
+		}
+		bds = append(bds, timeRoutes(lr, *duration))
+	}
+	printBreakdown(os.Stdout, s, bds, *top)
+	return nil
+}
+
+// breakdown is the time per request of each route a router loaded.
+type breakdown struct {
+	router  string
+	timings []routeTiming
+}
+
+type routeTiming struct {
+	route route
+	ns    float64
+}
+
+// timeRoutes times each route of lr on its own, by requesting it
+// repeatedly for at least d.
+func timeRoutes(lr *loadedRouter, d time.Duration) *breakdown {
+	bd := &breakdown{router: lr.router.Name()}
+	for i, req := range lr.requests {
+		bd.timings = append(bd.timings, routeTiming{lr.routes[i], timeRequest(lr, req, d)})
+	}
+	return bd
+}
+
+// timeRequest returns the time per request of router serving req, doubling
+// the number of requests until they take at least d.
+func timeRequest(router http.Handler, req request, d time.Duration) float64 {
+	w := new(mockResponseWriter)
+	r, _ := http.NewRequest(req.method, req.path, nil)
+	u := r.URL
+	rq := u.RawQuery
+	r.RequestURI = u.RequestURI()
+
+	for n := 1; ; n *= 2 {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			u.RawQuery = rq
+			router.ServeHTTP(w, r)
+		}
+		if elapsed := time.Since(start); elapsed >= d || n >= 1<<30 {
+			return float64(elapsed.Nanoseconds()) / float64(n)
+		}
+	}
+}
+
+// routeShapes are the ways routes are grouped by the shape of their path.
+var routeShapes = []struct {
+	title string
+	key   func(r route) (int, string)
+}{
+	{"path depth", func(r route) (int, string) {
+		n := len(r.segments())
+		return n, strconv.Itoa(n)
+	}},
+	{"parameters", func(r route) (int, string) {
+		n := 0
+		for _, seg := range r.segments() {
+			if seg.kind != staticSegment {
+				n++
+			}
+		}
+		return n, strconv.Itoa(n)
+	}},
+	{"kind", func(r route) (int, string) {
+		if r.needs()&(CapParams|CapCatchAll) == 0 {
+			return 0, "static"
+		}
+		return 1, "parameterized"
+	}},
+}
+
+// shapeGroup is the routes of a shape, with the mean time per request of
+// each router.
+type shapeGroup struct {
+	order  int
+	name   string
+	routes int
+	means  []float64
+}
+
+// shapeGroups groups the routes of bds by the key of a shape, ordered by
+// the key. The routes of the routers may differ by their coverage, a group
+// counts the routes of the first router.
+func shapeGroups(bds []*breakdown, key func(r route) (int, string)) []*shapeGroup {
+	byName := make(map[string]*shapeGroup)
+	var groups []*shapeGroup
+	sums := make(map[string][]float64)
+	counts := make(map[string][]int)
+	for i, bd := range bds {
+		for _, t := range bd.timings {
+			order, name := key(t.route)
+			g, ok := byName[name]
+			if !ok {
+				g = &shapeGroup{order: order, name: name, means: make([]float64, len(bds))}
+				byName[name] = g
+				groups = append(groups, g)
+				sums[name] = make([]float64, len(bds))
+				counts[name] = make([]int, len(bds))
+			}
+			sums[name][i] += t.ns
+			counts[name][i]++
+		}
+	}
+	for _, g := range groups {
+		for i := range bds {
+			if c := counts[g.name][i]; c > 0 {
+				g.means[i] = sums[g.name][i] / float64(c)
+				if g.routes == 0 {
+					g.routes = c
+				}
+			}
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].order < groups[j].order })
+	return groups
+}
+
+// slowest returns the n slowest routes of bd, slowest first.
+func (bd *breakdown) slowest(n int) []routeTiming {
+	ts := append([]routeTiming(nil), bd.timings...)
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].ns > ts[j].ns })
+	if len(ts) > n {
+		ts = ts[:n]
+	}
+	return ts
+}
+
+// median returns the median time per request of the routes of bd.
+func (bd *breakdown) median() float64 {
+	s := make(sample, len(bd.timings))
+	for i, t := range bd.timings {
+		s[i] = t.ns
+	}
+	return s.median()
+}
+
+// printBreakdown prints, for each shape, a table of the mean ns/req of the
+// routers by group, followed by the slowest routes of each router.
+func printBreakdown(w io.Writer, s *suite, bds []*breakdown, top int) {
+	if len(bds) == 0 {
+		return
+	}
+	for _, shape := range routeShapes {
+		fmt.Fprintf(w, "%s, mean ns/req by %s\n", s.name, shape.title)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "%s\troutes\t", shape.title)
+		for _, bd := range bds {
+			fmt.Fprintf(tw, "%s\t", bd.router)
+		}
+		fmt.Fprintln(tw)
+		for _, g := range shapeGroups(bds, shape.key) {
+			fmt.Fprintf(tw, "%s\t%d\t", g.name, g.routes)
+			for _, mean := range g.means {
+				if mean == 0 {
+					fmt.Fprint(tw, "-\t")
+				} else {
+					fmt.Fprintf(tw, "%.0f\t", mean)
+				}
+			}
+			fmt.Fprintln(tw)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%s, slowest routes\n", s.name)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, bd := range bds {
+		med := bd.median()
+		for i, t := range bd.slowest(top) {
+			name := ""
+			if i == 0 {
+				name = bd.router
+			}
+			fmt.Fprintf(tw, "%s\t%s %s\t%.0f ns/req\t%.1fx median\n", name, t.route.method, t.route.path, t.ns, t.ns/med)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBreakdown(t *testing.T) {
+	static := route{"GET", "/user/repos"}
+	param := route{"GET", "/users/:user/repos"}
+	deep := route{"GET", "/repos/:owner/:repo/git/refs/*ref"}
+	bds := []*breakdown{
+		{"Goji", []routeTiming{{static, 100}, {param, 200}, {deep, 600}}},
+		{"Martini", []routeTiming{{static, 1000}, {param, 3000}}},
+	}
+
+	groups := shapeGroups(bds, routeShapes[0].key)
+	if len(groups) != 3 || groups[0].name != "2" || groups[1].name != "3" || groups[2].name != "6" {
+		t.Fatalf("%d depth groups, want 2, 3 and 6", len(groups))
+	}
+	if groups[0].routes != 1 || groups[0].means[0] != 100 || groups[0].means[1] != 1000 || groups[2].means[1] != 0 {
+		t.Errorf("depth 2 group %+v, depth 6 group %+v", *groups[0], *groups[2])
+	}
+	groups = shapeGroups(bds, routeShapes[2].key)
+	if len(groups) != 2 || groups[1].name != "parameterized" || groups[1].routes != 2 || groups[1].means[0] != 400 {
+		t.Errorf("kind groups %+v, %+v", *groups[0], *groups[1])
+	}
+
+	if slow := bds[0].slowest(2); len(slow) != 2 || slow[0].route != deep || slow[1].route != param {
+		t.Errorf("slowest %+v", slow)
+	}
+
+	var buf bytes.Buffer
+	printBreakdown(&buf, githubSuite, bds, 1)
+	out := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{
+		"GithubAPI, mean ns/req by parameters parameters routes Goji Martini 0 1 100 1000 1 1 200 3000 3 1 600 -",
+		"Goji GET /repos/:owner/:repo/git/refs/*ref 600 ns/req 3.0x median",
+		"Martini GET /users/:user/repos 3000 ns/req 1.5x median",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\nlacks %q", buf.String(), want)
+		}
+	}
+}
+
+func TestTimeRoutes(t *testing.T) {
+	bd := timeRoutes(staticSuite.router(findRouter("HttpServeMux")), time.Microsecond)
+	if len(bd.timings) != len(staticSuite.routes) {
+		t.Fatalf("%d timings of %d routes", len(bd.timings), len(staticSuite.routes))
+	}
+	for _, rt := range bd.timings {
+		if rt.ns <= 0 {
+			t.Errorf("%s %s took %v ns", rt.route.method, rt.route.path, rt.ns)
+		}
+	}
+}
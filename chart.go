@@ -0,0 +1,320 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// chartColors are the colors of the routers' bars, by registration order,
+// so a router has the same color in every chart.
+var chartColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2",
+	"#59a14f", "#edc948", "#b07aa1", "#ff9da7",
+	"#9c755f", "#bab0ac",
+}
+
+// chartMetrics are the metrics of the charts per suite, with the name of
+// their files.
+var chartMetrics = []struct {
+	name, unit string
+	value      func(res *result) float64
+}{
+	{"time", "ns/op", func(res *result) float64 { return res.NsPerOp }},
+	{"bytes", "B/op", func(res *result) float64 { return float64(res.BytesPerOp) }},
+	{"allocs", "allocs/op", func(res *result) float64 { return float64(res.AllocsPerOp) }},
+}
+
+func chartCommand(args []string) error {
+	flags := newFlagSet("chart", "results")
+	dir := flags.String("dir", "charts", "write the charts to `dir`")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rr, err := readResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+
+	charts := resultCharts(reportResults(rr.Results))
+	if len(charts) == 0 {
+		return fmt.Errorf("%s: no serial results to chart", flags.Arg(0))
+	}
+	for name, c := range charts {
+		if err := writeChartFile(filepath.Join(*dir, name), c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChartFile(name string, c *barChart) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := c.writeSVG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resultCharts returns the charts of results by file name: a chart per suite
+// and metric of chartMetrics, with a group of bars per benchmark, and the
+// overview of the time per op of all benchmarks on a log scale.
+func resultCharts(results []result) map[string]*barChart {
+	charts := make(map[string]*barChart)
+	var all []string
+	for _, s := range suites {
+		var names []string
+		for _, bm := range s.benchmarks {
+			names = append(names, bm.name)
+		}
+		for _, m := range chartMetrics {
+			c := newBarChart(s.name+": "+m.unit, m.unit, names, results, m.value)
+			if c != nil {
+				charts[strings.ToLower(s.name)+"-"+m.name+".svg"] = c
+			}
+		}
+		all = append(all, names...)
+	}
+	if c := newBarChart("Time per op of all benchmarks", "ns/op", all, results, chartMetrics[0].value); c != nil {
+		c.log = true
+		charts["overview.svg"] = c
+	}
+	return charts
+}
+
+// barChart is a grouped bar chart with a bar per series in each group.
+type barChart struct {
+	title, unit string
+	groups      []string
+	series      []string
+
+	// colors are the colors of the series
+	colors []string
+
+	// values are indexed by group and series, NaN if missing
+	values [][]float64
+
+	// log sets a logarithmic value axis
+	log bool
+}
+
+// newBarChart returns a chart of the value of the results of the benchmarks
+// named by groups, with a series per router, or nil if there are none.
+// Groups without results are left out.
+func newBarChart(title, unit string, groups []string, results []result, value func(*result) float64) *barChart {
+	c := &barChart{title: title, unit: unit}
+	var series []int // indexes in routers
+	values := make(map[string]map[string]float64)
+	for i := range results {
+		res := &results[i]
+		if res.Error != "" {
+			continue
+		}
+		if values[res.Benchmark] == nil {
+			values[res.Benchmark] = make(map[string]float64)
+		}
+		values[res.Benchmark][res.Router] = value(res)
+	}
+	for i, r := range routers {
+		for _, g := range groups {
+			if _, ok := values[g][r.Name()]; ok {
+				series = append(series, i)
+				break
+			}
+		}
+	}
+	for _, i := range series {
+		c.series = append(c.series, routers[i].Name())
+		c.colors = append(c.colors, chartColors[i%len(chartColors)])
+	}
+
+	for _, g := range groups {
+		if values[g] == nil {
+			continue
+		}
+		row := make([]float64, len(c.series))
+		for i, name := range c.series {
+			v, ok := values[g][name]
+			if !ok {
+				v = math.NaN()
+			}
+			row[i] = v
+		}
+		c.groups = append(c.groups, g)
+		c.values = append(c.values, row)
+	}
+	if len(c.groups) == 0 {
+		return nil
+	}
+	return c
+}
+
+// The layout of charts in pixels.
+const (
+	chartBar     = 14  // width of a bar
+	chartGap     = 24  // space between groups
+	chartHeight  = 240 // height of the plot
+	chartLeft    = 64  // margin for the value axis
+	chartTop     = 32  // margin for the title, the legend lines go below
+	chartLegend  = 18  // height of a legend line
+	chartBottom  = 84  // margin for the group labels
+	chartRight   = 16
+	chartMinPlot = 400 // minimal width of the plot
+	chartFont    = `font-family="sans-serif" font-size="12"`
+)
+
+// ticks returns the values of the axis ticks, the first and last being the
+// bounds of the axis.
+func (c *barChart) ticks() []float64 {
+	min, max := math.Inf(1), 0.0
+	for _, row := range c.values {
+		for _, v := range row {
+			if math.IsNaN(v) {
+				continue
+			}
+			max = math.Max(max, v)
+			if v > 0 {
+				min = math.Min(min, v)
+			}
+		}
+	}
+
+	if c.log {
+		if math.IsInf(min, 1) {
+			return []float64{1, 10}
+		}
+		lo, hi := math.Floor(math.Log10(min)), math.Ceil(math.Log10(max))
+		if hi == lo {
+			hi++
+		}
+		var ticks []float64
+		for e := lo; e <= hi; e++ {
+			ticks = append(ticks, math.Pow(10, e))
+		}
+		return ticks
+	}
+
+	if max == 0 {
+		return []float64{0, 1}
+	}
+	// a step of 1, 2 or 5 times a power of 10 giving at most 5 steps
+	step := math.Pow(10, math.Floor(math.Log10(max/5)))
+	for _, f := range []float64{1, 2, 5, 10} {
+		if max/(f*step) <= 5 {
+			step *= f
+			break
+		}
+	}
+	var ticks []float64
+	for v := 0.0; ; v += step {
+		ticks = append(ticks, v)
+		if v >= max {
+			return ticks
+		}
+	}
+}
+
+// writeSVG writes c as a standalone SVG image. Each bar has a tooltip with
+// its value.
+func (c *barChart) writeSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	groupWidth := len(c.series)*chartBar + chartGap
+	plotWidth := len(c.groups) * groupWidth
+	if plotWidth < chartMinPlot {
+		plotWidth = chartMinPlot
+	}
+	width := chartLeft + plotWidth + chartRight
+
+	// the legend wraps at the width of the chart
+	type entry struct{ x, y int }
+	legend := make([]entry, len(c.series))
+	x, top := chartLeft, chartTop+chartLegend
+	for i, name := range c.series {
+		lw := 14 + 7*len(name) + 16
+		if x > chartLeft && x+lw > width {
+			x, top = chartLeft, top+chartLegend
+		}
+		legend[i] = entry{x, top - chartLegend}
+		x += lw
+	}
+	top += chartLegend / 2
+	height := top + chartHeight + chartBottom
+
+	ticks := c.ticks()
+	lo, hi := ticks[0], ticks[len(ticks)-1]
+	y := func(v float64) float64 {
+		f := (v - lo) / (hi - lo)
+		if c.log {
+			f = math.Log10(v/lo) / math.Log10(hi/lo)
+		}
+		return float64(top+chartHeight) - f*chartHeight
+	}
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" %s>`+"\n",
+		width, height, width, height, chartFont)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	title := c.title
+	if c.log {
+		title += " (log scale)"
+	}
+	fmt.Fprintf(bw, `<text x="%d" y="20" font-size="14" font-weight="bold">%s</text>`+"\n", chartLeft, html.EscapeString(title))
+
+	for i, e := range legend {
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, e.x, e.y+4, c.colors[i])
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", e.x+14, e.y+13, html.EscapeString(c.series[i]))
+	}
+
+	// value axis with grid lines
+	for _, t := range ticks {
+		ty := y(t)
+		fmt.Fprintf(bw, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#dddddd"/>`, chartLeft, ty, chartLeft+plotWidth, ty)
+		fmt.Fprintf(bw, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", chartLeft-6, ty+4, formatValue(t))
+	}
+	fmt.Fprintf(bw, `<text transform="translate(14 %d) rotate(-90)" text-anchor="middle">%s</text>`+"\n",
+		top+chartHeight/2, html.EscapeString(c.unit))
+
+	// bars and group labels
+	for g, name := range c.groups {
+		gx := chartLeft + g*groupWidth + chartGap/2
+		for i, v := range c.values[g] {
+			if math.IsNaN(v) || (c.log && v <= 0) {
+				continue
+			}
+			by := y(v)
+			fmt.Fprintf(bw, `<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s"><title>%s %s: %s %s</title></rect>`+"\n",
+				gx+i*chartBar, by, chartBar-2, float64(top+chartHeight)-by, c.colors[i],
+				html.EscapeString(c.series[i]), html.EscapeString(name), formatValue(v), html.EscapeString(c.unit))
+		}
+		lx := gx + len(c.series)*chartBar/2
+		fmt.Fprintf(bw, `<text transform="translate(%d %d) rotate(-35)" text-anchor="end">%s</text>`+"\n",
+			lx, top+chartHeight+16, html.EscapeString(name))
+	}
+	fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#000000"/>`+"\n",
+		chartLeft, top+chartHeight, chartLeft+plotWidth, top+chartHeight)
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
@@ -0,0 +1,82 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestResultCharts(t *testing.T) {
+	results := []result{
+		{Benchmark: "GithubStatic", Router: "Goji", Mode: "serial", Procs: 1, NsPerOp: 120, BytesPerOp: 0, AllocsPerOp: 0},
+		{Benchmark: "GithubAll", Router: "Goji", Mode: "serial", Procs: 1, NsPerOp: 55022, BytesPerOp: 63172, AllocsPerOp: 376},
+		{Benchmark: "GithubAll", Router: "Martini", Mode: "serial", Procs: 1, NsPerOp: 1130000, BytesPerOp: 151360, AllocsPerOp: 2410},
+		{Benchmark: "GithubAll", Router: "Broken", Mode: "serial", Procs: 1, Error: "FAILED routing verification"},
+		{Benchmark: "ParseAll", Router: "Beego", Mode: "serial", Procs: 1, NsPerOp: 33000, BytesPerOp: 2100, AllocsPerOp: 26},
+	}
+	charts := resultCharts(results)
+
+	var names []string
+	for _, name := range []string{"githubapi-time.svg", "githubapi-bytes.svg", "githubapi-allocs.svg",
+		"parseapi-time.svg", "parseapi-bytes.svg", "parseapi-allocs.svg", "overview.svg"} {
+		if charts[name] == nil {
+			t.Errorf("no chart %s", name)
+		}
+		names = append(names, name)
+	}
+	if len(charts) != len(names) {
+		t.Errorf("%d charts, want %v", len(charts), names)
+	}
+
+	c := charts["githubapi-time.svg"]
+	if !reflect.DeepEqual(c.groups, []string{"GithubStatic", "GithubAll"}) {
+		t.Errorf("groups %v, want [GithubStatic GithubAll]", c.groups)
+	}
+	if !reflect.DeepEqual(c.series, []string{"Goji", "Martini"}) {
+		t.Errorf("series %v, want [Goji Martini]", c.series)
+	}
+	if c.colors[0] != chartColors[1] || c.colors[1] != chartColors[5] {
+		t.Errorf("colors %v not by router registration order", c.colors)
+	}
+
+	o := charts["overview.svg"]
+	if !o.log || len(o.groups) != 3 {
+		t.Errorf("overview with log %v and groups %v", o.log, o.groups)
+	}
+	if ticks := o.ticks(); !reflect.DeepEqual(ticks, []float64{100, 1000, 1e4, 1e5, 1e6, 1e7}) {
+		t.Errorf("log ticks %v", ticks)
+	}
+	if ticks := charts["githubapi-allocs.svg"].ticks(); !reflect.DeepEqual(ticks, []float64{0, 500, 1000, 1500, 2000, 2500}) {
+		t.Errorf("linear ticks %v", ticks)
+	}
+
+	// the SVG is well-formed and has a bar per value with a tooltip
+	var buf bytes.Buffer
+	if err := c.writeSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(&buf)
+	bars := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "title" {
+			bars++
+		}
+	}
+	// Martini has no GithubStatic result
+	if bars != 3 {
+		t.Errorf("%d bars, want 3", bars)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// compareUnits are the metrics compare reports, in this order.
+var compareUnits = []string{
+	"ns/op", "B/op", "allocs/op",
+	"mem-B", "mem-B/route", "load-B", "load-allocs",
+}
+
+func compareCommand(args []string) error {
+	flags := newFlagSet("compare", "old new")
+	alpha := flags.Float64("alpha", 0.05, "consider changes significant if p < `alpha`")
+	confidence := flags.Float64("confidence", 0.95, "confidence `level` of the intervals of the medians")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var runs [2]*runResults
+	for i := range runs {
+		f, err := os.Open(flags.Arg(i))
+		if err != nil {
+			return err
+		}
+		runs[i], err = readResults(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", flags.Arg(i), err)
+		}
+	}
+	compare(os.Stdout, runs[0], runs[1], *alpha, *confidence)
+	return nil
+}
+
+// comparison is a benchmark in the old and the new run.
+type comparison struct {
+	name     string
+	old, new []result
+}
+
+// samples returns the values of the metric with the given unit of the
+// results without an error.
+func samples(results []result, unit string) sample {
+	var s sample
+	for i := range results {
+		if results[i].Error != "" {
+			continue
+		}
+		for _, m := range results[i].metrics() {
+			if m.unit == unit {
+				s = append(s, m.value)
+			}
+		}
+	}
+	return s
+}
+
+// compare prints the differences of the environments of old and new, then a
+// table per metric comparing their benchmarks, matched by their keys. Each
+// side shows the median of the repeated runs and its confidence interval,
+// the delta is that of the medians. Deltas with a p-value of the
+// Mann-Whitney U test not below alpha are printed as ~.
+func compare(w io.Writer, old, new *runResults, alpha, confidence float64) {
+	var cmps []*comparison
+	byKey := make(map[string]*comparison)
+	add := func(results []result, side func(*comparison) *[]result) {
+		for _, res := range results {
+			k := res.key()
+			c, ok := byKey[k]
+			if !ok {
+				name := strings.TrimPrefix(res.benchName(), "Benchmark")
+				c = &comparison{name: name}
+				byKey[k] = c
+				cmps = append(cmps, c)
+			}
+			*side(c) = append(*side(c), res)
+		}
+	}
+	add(old.Results, func(c *comparison) *[]result { return &c.old })
+	add(new.Results, func(c *comparison) *[]result { return &c.new })
+
+	if diff := configDiff(old.Env, new.Env); len(diff) > 0 {
+		fmt.Fprintln(w, "environment differs:")
+		for _, line := range diff {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, unit := range compareUnits {
+		var rows []string
+		for _, c := range cmps {
+			x, y := samples(c.old, unit), samples(c.new, unit)
+			switch {
+			case len(x) > 0 || len(y) > 0:
+				rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t%s\n", c.name,
+					summary(x, c.old, confidence), summary(y, c.new, confidence), delta(x, y, alpha)))
+			case unit == compareUnits[0]:
+				// both sides failed, which is worth a row once
+				rows = append(rows, fmt.Sprintf("%s\t%s\t%s\t\n", c.name, failedCell(c.old), failedCell(c.new)))
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(tw, "name\told %s\tnew %s\tdelta\n", unit, unit)
+		for _, row := range rows {
+			io.WriteString(tw, row)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+}
+
+// failedCell returns the cell of a side without samples.
+func failedCell(results []result) string {
+	if len(results) == 0 {
+		return ""
+	}
+	return "FAILED"
+}
+
+// summary returns the median of s with the larger distance of the bounds of
+// its confidence interval as a percentage, or ±∞ if s is too small for the
+// confidence level.
+func summary(s sample, results []result, confidence float64) string {
+	if len(s) == 0 {
+		return failedCell(results)
+	}
+	med := s.median()
+	lo, hi, ok := s.medianCI(confidence)
+	switch {
+	case !ok:
+		return formatValue(med) + " ± ∞"
+	case med == 0:
+		return formatValue(med)
+	}
+	dev := math.Max(med-lo, hi-med) / med * 100
+	return fmt.Sprintf("%s ± %.0f%%", formatValue(med), dev)
+}
+
+// delta returns the change of the median from x to y with the p-value and
+// the sample sizes, or ~ if it is not significant.
+func delta(x, y sample, alpha float64) string {
+	if len(x) == 0 || len(y) == 0 {
+		return ""
+	}
+	p := mannWhitneyU(x, y)
+	n := strconv.Itoa(len(x))
+	if len(y) != len(x) {
+		n += "+" + strconv.Itoa(len(y))
+	}
+	info := fmt.Sprintf("(p=%.3f n=%s)", p, n)
+	if p >= alpha {
+		return "~ " + info
+	}
+	old, new := x.median(), y.median()
+	if old == 0 {
+		return "+∞% " + info
+	}
+	return fmt.Sprintf("%+.2f%% %s", (new-old)/old*100, info)
+}
+
+// formatValue formats v with 3 to 4 significant digits and an SI prefix.
+func formatValue(v float64) string {
+	prefix := ""
+	for _, p := range []struct {
+		scale  float64
+		prefix string
+	}{{1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if math.Abs(v) >= p.scale {
+			v /= p.scale
+			prefix = p.prefix
+			break
+		}
+	}
+	prec := 2
+	switch {
+	case v == math.Trunc(v) && prefix == "":
+		prec = 0
+	case math.Abs(v) >= 100:
+		prec = 0
+	case math.Abs(v) >= 10:
+		prec = 1
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64) + prefix
+}
@@ -0,0 +1,71 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	run := func(router string, ns []float64, bytes int64, mem uint64) []result {
+		var results []result
+		for _, v := range ns {
+			results = append(results, result{
+				Suite: "GithubAPI", Benchmark: "GithubAll", Router: router, Mode: "serial", Procs: 1,
+				N: 1000, NsPerOp: v, BytesPerOp: bytes, AllocsPerOp: 10, Memory: &memory{Bytes: mem},
+			})
+		}
+		return results
+	}
+	old := &runResults{Results: append(
+		run("Goji", []float64{1000, 1010, 990, 1005, 995, 1002}, 64, 86088),
+		run("Martini", []float64{2000, 2100, 1900, 2050, 1950}, 512, 476960)...)}
+	new := &runResults{Results: append(append(
+		run("Goji", []float64{900, 905, 895, 910, 890, 902}, 64, 86088),
+		run("Martini", []float64{1980, 2120, 1910, 2040, 1960}, 512, 476960)...),
+		result{Benchmark: "GithubAll", Router: "Broken", Mode: "serial", Procs: 1, Error: "FAILED routing verification"})}
+
+	var buf bytes.Buffer
+	compare(&buf, old, new, 0.05, 0.95)
+	// tabwriter padding aside
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	out := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"name old ns/op new ns/op delta\n",
+		"GithubAll/Goji 1.00k ± 1% 901 ± 1% -9.99% (p=0.002 n=6)\n",
+		"GithubAll/Martini 2.00k ± ∞ 1.98k ± ∞ ~ (p=1.000 n=5)\n",
+		"GithubAll/Broken FAILED\n",
+		"GithubAll/Goji 64 ± 0% 64 ± 0% ~ (p=1.000 n=6)\n",
+		"GithubAll/Martini 477k ± ∞ 477k ± ∞ ~ (p=1.000 n=5)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\nlacks %q", buf.String(), want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for v, want := range map[float64]string{
+		0:        "0",
+		42:       "42",
+		3.14159:  "3.14",
+		950.5:    "950",
+		1234:     "1.23k",
+		63172:    "63.2k",
+		550221:   "550k",
+		1494864:  "1.49M",
+		-12.5e9:  "-12.5G",
+		12.34567: "12.3",
+	} {
+		if got := formatValue(v); got != want {
+			t.Errorf("formatValue(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// environment describes the system a run ran on and what it ran.
+type environment struct {
+	Date      time.Time `json:"date"`
+	GoVersion string    `json:"go_version,omitempty"`
+	GOOS      string    `json:"goos,omitempty"`
+	GOARCH    string    `json:"goarch,omitempty"`
+
+	// CPU is the model name, Cores and Threads the number of physical and
+	// logical cores.
+	CPU     string `json:"cpu,omitempty"`
+	Cores   int    `json:"cores,omitempty"`
+	Threads int    `json:"threads,omitempty"`
+
+	// GOMAXPROCS and GOGC are the settings of the runtime. Benchmarks run
+	// with another GOMAXPROCS have it in their results.
+	GOMAXPROCS int    `json:"gomaxprocs,omitempty"`
+	GOGC       string `json:"gogc,omitempty"`
+
+	// Commit is the git commit of the benchmark, suffixed by -dirty if
+	// there were uncommitted changes.
+	Commit string `json:"commit,omitempty"`
+
+	// Routers are the module versions of the routers by name, as
+	// path@version.
+	Routers map[string]string `json:"routers,omitempty"`
+}
+
+// gcPercent returns the GC percentage the runtime reads from the GOGC
+// variable value, which is 100 if it is unset or invalid.
+func gcPercent(value string) string {
+	if value == "off" {
+		return value
+	}
+	n, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		return "100"
+	case n < 0:
+		return "off"
+	}
+	return strconv.Itoa(n)
+}
+
+// captureEnv returns the environment of the running process. The CPU is
+// read from /proc/cpuinfo where there is one.
+func captureEnv() environment {
+	env := environment{
+		Date:       time.Now().UTC().Truncate(time.Second),
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GOGC:       gcPercent(os.Getenv("GOGC")),
+	}
+	if f, err := os.Open("/proc/cpuinfo"); err == nil {
+		env.CPU, env.Cores, env.Threads = cpuInfo(f)
+		f.Close()
+	}
+	if env.Threads == 0 {
+		env.Threads = runtime.NumCPU()
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		bi = new(debug.BuildInfo)
+	}
+	env.Routers = routerVersions(bi)
+	env.Commit = gitCommit(bi)
+	return env
+}
+
+// cpuInfo returns the model name and the number of physical and logical
+// cores listed by /proc/cpuinfo. Without the physical and core ids, each
+// logical core counts as a physical one.
+func cpuInfo(r io.Reader) (model string, cores, threads int) {
+	ids := make(map[string]bool)
+	var physical string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		i := strings.Index(sc.Text(), ":")
+		if i < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(sc.Text()[:i]), strings.TrimSpace(sc.Text()[i+1:])
+		switch key {
+		case "processor":
+			threads++
+		case "model name":
+			if model == "" {
+				model = value
+			}
+		case "physical id":
+			physical = value
+		case "core id":
+			ids[physical+"/"+value] = true
+		}
+	}
+	cores = len(ids)
+	if cores == 0 {
+		cores = threads
+	}
+	return model, cores, threads
+}
+
+// routerVersions returns the module versions of the routers by name. The
+// standard library has the version of Go, routers of modules missing in the
+// build info are left out.
+func routerVersions(bi *debug.BuildInfo) map[string]string {
+	versions := make(map[string]string)
+	for _, r := range routers {
+		pkg := r.Package()
+		if !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".") {
+			versions[r.Name()] = "std@" + runtime.Version()
+			continue
+		}
+		var found *debug.Module
+		for _, m := range bi.Deps {
+			if (pkg == m.Path || strings.HasPrefix(pkg, m.Path+"/")) && (found == nil || len(m.Path) > len(found.Path)) {
+				found = m
+			}
+		}
+		if found == nil {
+			continue
+		}
+		version := found.Version
+		if found.Replace != nil && found.Replace.Version != "" {
+			version = found.Replace.Version
+		}
+		versions[r.Name()] = found.Path + "@" + version
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions
+}
+
+// gitCommit returns the git commit stamped into the binary or, as go test
+// does not stamp it, the one of the working directory.
+func gitCommit(bi *debug.BuildInfo) string {
+	var commit string
+	dirty := false
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			commit = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if commit == "" {
+		out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		if err != nil {
+			return ""
+		}
+		commit = strings.TrimSpace(string(out))
+		status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+		dirty = err == nil && len(status) > 0
+	}
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if dirty {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// routerConfigPrefix prefixes the configuration keys of router versions,
+// followed by the router name in lower case.
+const routerConfigPrefix = "router-"
+
+// config returns env as configuration lines of go test -bench output,
+// "key: value" with a lower-case key, in a fixed order.
+func (env environment) config() [][2]string {
+	var lines [][2]string
+	add := func(key, value string) {
+		if value != "" && value != "0" {
+			lines = append(lines, [2]string{key, value})
+		}
+	}
+	if !env.Date.IsZero() {
+		add("date", env.Date.Format(time.RFC3339))
+	}
+	add("go", env.GoVersion)
+	add("goos", env.GOOS)
+	add("goarch", env.GOARCH)
+	add("cpu", env.CPU)
+	add("cores", strconv.Itoa(env.Cores))
+	add("threads", strconv.Itoa(env.Threads))
+	add("gomaxprocs", strconv.Itoa(env.GOMAXPROCS))
+	add("gogc", env.GOGC)
+	add("commit", env.Commit)
+	for _, r := range routers {
+		add(routerConfigPrefix+strings.ToLower(r.Name()), env.Routers[r.Name()])
+	}
+	return lines
+}
+
+// printConfig prints the configuration lines of env, but the ones with the
+// keys to skip.
+func (env environment) printConfig(w io.Writer, skip ...string) {
+lines:
+	for _, kv := range env.config() {
+		for _, key := range skip {
+			if kv[0] == key {
+				continue lines
+			}
+		}
+		fmt.Fprintf(w, "%s: %s\n", kv[0], kv[1])
+	}
+}
+
+// setConfig sets the field of env with the configuration key, as printed by
+// printConfig. It returns false for unknown keys.
+func (env *environment) setConfig(key, value string) bool {
+	atoi := func(p *int) {
+		*p, _ = strconv.Atoi(value)
+	}
+	switch key {
+	case "date":
+		env.Date, _ = time.Parse(time.RFC3339, value)
+	case "go":
+		env.GoVersion = value
+	case "goos":
+		env.GOOS = value
+	case "goarch":
+		env.GOARCH = value
+	case "cpu":
+		env.CPU = value
+	case "cores":
+		atoi(&env.Cores)
+	case "threads":
+		atoi(&env.Threads)
+	case "gomaxprocs":
+		atoi(&env.GOMAXPROCS)
+	case "gogc":
+		env.GOGC = value
+	case "commit":
+		env.Commit = value
+	default:
+		r := findRouter(strings.TrimPrefix(key, routerConfigPrefix))
+		if !strings.HasPrefix(key, routerConfigPrefix) || r == nil {
+			return false
+		}
+		if env.Routers == nil {
+			env.Routers = make(map[string]string)
+		}
+		env.Routers[r.Name()] = value
+	}
+	return true
+}
+
+// configDiff returns the configuration lines differing between old and new
+// as "key: old → new", leaving out the date.
+func configDiff(old, new environment) []string {
+	values := make(map[string][2]string)
+	var keys []string
+	for i, env := range []environment{old, new} {
+		for _, kv := range env.config() {
+			if kv[0] == "date" {
+				continue
+			}
+			v, ok := values[kv[0]]
+			if !ok {
+				keys = append(keys, kv[0])
+			}
+			v[i] = kv[1]
+			values[kv[0]] = v
+		}
+	}
+	var diff []string
+	for _, key := range keys {
+		if v := values[key]; v[0] != v[1] {
+			diff = append(diff, fmt.Sprintf("%s: %s → %s", key, orNone(v[0]), orNone(v[1])))
+		}
+	}
+	return diff
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
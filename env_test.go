@@ -0,0 +1,113 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cpuinfo = `processor	: 0
+model name	: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+physical id	: 0
+core id		: 0
+
+processor	: 1
+model name	: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+physical id	: 0
+core id		: 1
+
+processor	: 2
+model name	: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+physical id	: 0
+core id		: 0
+
+processor	: 3
+model name	: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+physical id	: 0
+core id		: 1
+`
+
+func TestCPUInfo(t *testing.T) {
+	model, cores, threads := cpuInfo(strings.NewReader(cpuinfo))
+	if model != "Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz" || cores != 2 || threads != 4 {
+		t.Errorf("cpuInfo = %q, %d cores, %d threads", model, cores, threads)
+	}
+	// no core ids, as on some ARM systems
+	_, cores, threads = cpuInfo(strings.NewReader("processor : 0\n\nprocessor : 1\n"))
+	if cores != 2 || threads != 2 {
+		t.Errorf("cpuInfo without core ids = %d cores, %d threads", cores, threads)
+	}
+}
+
+func TestGCPercent(t *testing.T) {
+	for value, want := range map[string]string{"": "100", "off": "off", "200": "200", "-1": "off", "x": "100"} {
+		if got := gcPercent(value); got != want {
+			t.Errorf("gcPercent(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestRouterVersions(t *testing.T) {
+	bi := &debug.BuildInfo{Deps: []*debug.Module{
+		{Path: "github.com/zenazn/goji", Version: "v1.0.1"},
+		{Path: "github.com/gorilla/mux", Version: "v1.8.0", Replace: &debug.Module{Path: "../mux", Version: "v1.8.1"}},
+		{Path: "github.com/gorilla/muxer", Version: "v0.1.0"},
+	}}
+	want := map[string]string{
+		"Goji":         "github.com/zenazn/goji@v1.0.1",
+		"GorillaMux":   "github.com/gorilla/mux@v1.8.1",
+		"HttpServeMux": "std@" + runtime.Version(),
+	}
+	if got := routerVersions(bi); !reflect.DeepEqual(got, want) {
+		t.Errorf("routerVersions = %v, want %v", got, want)
+	}
+}
+
+func TestEnvConfig(t *testing.T) {
+	env := environment{
+		Date:       time.Date(2017, 2, 14, 10, 0, 0, 0, time.UTC),
+		GoVersion:  "go1.7.5",
+		GOOS:       "linux",
+		GOARCH:     "amd64",
+		CPU:        "Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz",
+		Cores:      4,
+		Threads:    8,
+		GOMAXPROCS: 8,
+		GOGC:       "100",
+		Commit:     "0123456789ab-dirty",
+		Routers:    map[string]string{"Goji": "github.com/zenazn/goji@v1.0.1"},
+	}
+
+	// the bench format keeps the environment
+	var buf bytes.Buffer
+	env.printConfig(&buf)
+	buf.WriteString("BenchmarkGithubAll/Goji-8\t100\t550221 ns/op\n")
+	rr, err := parseBenchOutput(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rr.Env, env) {
+		t.Errorf("environment\n%+v\nwant\n%+v", rr.Env, env)
+	}
+
+	other := env
+	other.Date = env.Date.Add(time.Hour)
+	other.CPU = "AMD EPYC 7B13"
+	other.Routers = map[string]string{"Goji": "github.com/zenazn/goji@v1.0.0", "Beego": "github.com/astaxie/beego@v1.12.3"}
+	want := []string{
+		"cpu: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz → AMD EPYC 7B13",
+		"router-goji: github.com/zenazn/goji@v1.0.1 → github.com/zenazn/goji@v1.0.0",
+		"router-beego: none → github.com/astaxie/beego@v1.12.3",
+	}
+	if diff := configDiff(env, other); !reflect.DeepEqual(diff, want) {
+		t.Errorf("configDiff = %q, want %q", diff, want)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+// Google+
+// https://developers.google.com/+/api/latest/
+// (in reality this is just a subset of a much larger API)
+var gplusAPI = []route{
+	// People
+	{"GET", "/people/:userId"},
+	{"GET", "/people"},
+	{"GET", "/activities/:activityId/people/:collection"},
+	{"GET", "/people/:userId/people/:collection"},
+	{"GET", "/people/:userId/openIdConnect"},
+
+	// Activities
+	{"GET", "/people/:userId/activities/:collection"},
+	{"GET", "/activities/:activityId"},
+	{"GET", "/activities"},
+
+	// Comments
+	{"GET", "/activities/:activityId/comments"},
+	{"GET", "/comments/:commentId"},
+
+	// Moments
+	{"POST", "/people/:userId/moments/:collection"},
+	{"GET", "/people/:userId/moments/:collection"},
+	{"DELETE", "/moments/:id"},
+}
+
+var gplusSuite = registerSuite(&suite{
+	name:   "GPlusAPI",
+	routes: gplusAPI,
+	benchmarks: []benchmark{
+		{"GPlusStatic", "/people"},
+		{"GPlusParam", "/people/118051310819094153327"},
+		{"GPlus2Params", "/people/118051310819094153327/activities/123456789"},
+		{"GPlusAll", ""},
+	},
+})
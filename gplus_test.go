@@ -5,147 +5,25 @@
 package main
 
 import (
-	"net/http"
 	"testing"
 )
 
-// Google+
-// https://developers.google.com/+/api/latest/
-// (in reality this is just a subset of a much larger API)
-var gplusAPI = []route{
-	// People
-	{"GET", "/people/:userId"},
-	{"GET", "/people"},
-	{"GET", "/activities/:activityId/people/:collection"},
-	{"GET", "/people/:userId/people/:collection"},
-	{"GET", "/people/:userId/openIdConnect"},
-
-	// Activities
-	{"GET", "/people/:userId/activities/:collection"},
-	{"GET", "/activities/:activityId"},
-	{"GET", "/activities"},
-
-	// Comments
-	{"GET", "/activities/:activityId/comments"},
-	{"GET", "/comments/:commentId"},
-
-	// Moments
-	{"POST", "/people/:userId/moments/:collection"},
-	{"GET", "/people/:userId/moments/:collection"},
-	{"DELETE", "/moments/:id"},
-}
-
-var (
-	gplusBeego      http.Handler
-	gplusGin        http.Handler
-	gplusGoji       http.Handler
-	gplusGorillaMux http.Handler
-	gplusHttpRouter http.Handler
-	gplusMartini    http.Handler
-	gplusMacaron    http.Handler
-)
-
-func init() {
-	println("#GPlusAPI Routes:", len(gplusAPI))
-
-	calcMem("Beego", func() {
-		gplusBeego = loadBeego(gplusAPI)
-	})
-	calcMem("Goji", func() {
-		gplusGoji = loadGoji(gplusAPI)
-	})
-	calcMem("GorillaMux", func() {
-		gplusGorillaMux = loadGorillaMux(gplusAPI)
-	})
-	calcMem("Martini", func() {
-		gplusMartini = loadMartini(gplusAPI)
-	})
-	calcMem("Macaron", func() {
-		gplusMacaron = loadMacaron(gplusAPI)
-	})
-
-	println()
-}
-
 // Static
-func BenchmarkBeego_GPlusStatic(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people", nil)
-	benchRequest(b, gplusBeego, req)
-}
-func BenchmarkGoji_GPlusStatic(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people", nil)
-	benchRequest(b, gplusGoji, req)
-}
-func BenchmarkGorillaMux_GPlusStatic(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people", nil)
-	benchRequest(b, gplusGorillaMux, req)
-}
-func BenchmarkMartini_GPlusStatic(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people", nil)
-	benchRequest(b, gplusMartini, req)
-}
-func BenchmarkMacaron_GPlusStatic(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people", nil)
-	benchRequest(b, gplusMacaron, req)
+func BenchmarkGPlusStatic(b *testing.B) {
+	gplusSuite.run(b, "GPlusStatic")
 }
 
 // One Param
-func BenchmarkBeego_GPlusParam(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327", nil)
-	benchRequest(b, gplusBeego, req)
-}
-func BenchmarkGoji_GPlusParam(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327", nil)
-	benchRequest(b, gplusGoji, req)
-}
-func BenchmarkGorillaMux_GPlusParam(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327", nil)
-	benchRequest(b, gplusGorillaMux, req)
-}
-func BenchmarkMartini_GPlusParam(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327", nil)
-	benchRequest(b, gplusMartini, req)
-}
-func BenchmarkMacaron_GPlusParam(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327", nil)
-	benchRequest(b, gplusMacaron, req)
+func BenchmarkGPlusParam(b *testing.B) {
+	gplusSuite.run(b, "GPlusParam")
 }
 
 // Two Params
-func BenchmarkBeego_GPlus2Params(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327/activities/123456789", nil)
-	benchRequest(b, gplusBeego, req)
-}
-func BenchmarkGoji_GPlus2Params(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327/activities/123456789", nil)
-	benchRequest(b, gplusGoji, req)
-}
-func BenchmarkGorillaMux_GPlus2Params(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327/activities/123456789", nil)
-	benchRequest(b, gplusGorillaMux, req)
-}
-func BenchmarkMartini_GPlusParam2(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327/activities/123456789", nil)
-	benchRequest(b, gplusMartini, req)
-}
-func BenchmarkMacaron_GPlusParam2(b *testing.B) {
-	req, _ := http.NewRequest("GET", "/people/118051310819094153327/activities/123456789", nil)
-	benchRequest(b, gplusMacaron, req)
+func BenchmarkGPlus2Params(b *testing.B) {
+	gplusSuite.run(b, "GPlus2Params")
 }
 
 // All Routes
-func BenchmarkBeego_GPlusAll(b *testing.B) {
-	benchRoutes(b, gplusBeego, gplusAPI)
-}
-func BenchmarkGoji_GPlusAll(b *testing.B) {
-	benchRoutes(b, gplusGoji, gplusAPI)
-}
-func BenchmarkGorillaMux_GPlusAll(b *testing.B) {
-	benchRoutes(b, gplusGorillaMux, gplusAPI)
-}
-func BenchmarkMartini_GPlusAll(b *testing.B) {
-	benchRoutes(b, gplusMartini, gplusAPI)
-}
-func BenchmarkMacaron_GPlusAll(b *testing.B) {
-	benchRoutes(b, gplusMacaron, gplusAPI)
+func BenchmarkGPlusAll(b *testing.B) {
+	gplusSuite.run(b, "GPlusAll")
 }
@@ -0,0 +1,283 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// A history is a directory of runs, each saved as a JSON file by writeJSON
+// with its environment. The files are named by the date and the commit of
+// the run, so they sort chronologically:
+//
+//	history/20170214T100000Z-0123456789ab.json
+const historyTimeFormat = "20060102T150405Z"
+
+func saveCommand(args []string) error {
+	flags := newFlagSet("save", "results")
+	dir := flags.String("dir", "history", "the history `dir`")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rr, err := readResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	name, err := saveRun(*dir, rr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "saved", name)
+	return nil
+}
+
+func trendCommand(args []string) error {
+	flags := newFlagSet("trend", "")
+	var (
+		dir        = flags.String("dir", "history", "the history `dir`")
+		suiteList  = flags.String("suite", "", "comma-separated suites to show, all if empty")
+		routerList = flags.String("router", "", "comma-separated routers to show, all if empty")
+		modeName   = flags.String("mode", "serial", "the mode to show: "+strings.Join(modeNames, ", "))
+		top        = flags.Int("top", 10, "flag the `n` largest regressions")
+		threshold  = flags.Float64("threshold", 5, "flag regressions above `percent`")
+	)
+	flags.Parse(args)
+
+	cfg, err := newRunConfig(*suiteList, *routerList, *modeName, 1)
+	if err != nil {
+		return err
+	}
+	if len(cfg.modes) != 1 {
+		return fmt.Errorf("trend shows a single mode")
+	}
+	runs, err := loadHistory(*dir)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no runs in %s, add some with the save command or run -history", *dir)
+	}
+	printTrend(os.Stdout, trends(runs, cfg), *top, *threshold)
+	return nil
+}
+
+// saveRun saves rr in the history dir, which is created if missing, and
+// returns the name of the file.
+func saveRun(dir string, rr *runResults) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := rr.Env.Date.UTC().Format(historyTimeFormat)
+	if rr.Env.Commit != "" {
+		name += "-" + rr.Env.Commit
+	}
+	name = filepath.Join(dir, name+".json")
+	if _, err := os.Stat(name); err == nil {
+		return "", fmt.Errorf("%s already exists", name)
+	}
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, rr); err != nil {
+		return "", err
+	}
+	return name, ioutil.WriteFile(name, buf.Bytes(), 0644)
+}
+
+// loadHistory returns the runs of the history dir, oldest first.
+func loadHistory(dir string) ([]*runResults, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*runResults, 0, len(names))
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		rr, err := readResults(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		runs = append(runs, rr)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Env.Date.Before(runs[j].Env.Date) })
+	return runs, nil
+}
+
+// trendMetrics are the metrics a trend follows.
+var trendMetrics = []struct {
+	unit  string
+	value func(res *result) (float64, bool)
+}{
+	{"ns/op", func(res *result) (float64, bool) { return res.NsPerOp, true }},
+	{"allocs/op", func(res *result) (float64, bool) { return float64(res.AllocsPerOp), true }},
+	{"mem-B", func(res *result) (float64, bool) {
+		if res.Memory == nil {
+			return 0, false
+		}
+		return float64(res.Memory.Bytes), true
+	}},
+}
+
+// trend is a benchmark of a router across the runs of a history.
+type trend struct {
+	benchmark, router string
+	points            []trendPoint
+}
+
+// trendPoint is the median result of a run, with the run's environment.
+type trendPoint struct {
+	env environment
+	res result
+}
+
+// trends returns the trends of the benchmarks cfg selects, in the order of
+// the suites, their benchmarks and the routers. Runs without results for a
+// benchmark leave no point, benchmarks in no run no trend.
+func trends(runs []*runResults, cfg *runConfig) []*trend {
+	m := cfg.modes[0]
+	byKey := make(map[string]*trend)
+	for _, rr := range runs {
+		for _, res := range medianResults(rr.Results) {
+			if res.Mode != m.String() || res.Error != "" {
+				continue
+			}
+			k := res.key()
+			t, ok := byKey[k]
+			if !ok {
+				t = &trend{benchmark: res.Benchmark, router: res.Router}
+				byKey[k] = t
+			}
+			t.points = append(t.points, trendPoint{rr.Env, res})
+		}
+	}
+
+	var ts []*trend
+	for _, s := range cfg.suites {
+		for _, bm := range s.benchmarks {
+			for _, r := range cfg.routers {
+				// a trend per GOMAXPROCS the benchmark ran with
+				var keys []string
+				for k, t := range byKey {
+					if t.benchmark == bm.name && t.router == r.Name() {
+						keys = append(keys, k)
+					}
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					ts = append(ts, byKey[k])
+				}
+			}
+		}
+	}
+	return ts
+}
+
+// regression is the change of a metric of a trend from its previous to its
+// latest point.
+type regression struct {
+	t       *trend
+	unit    string
+	percent float64
+}
+
+// regressions returns the regressions of the latest points of ts above the
+// threshold percentage, largest first.
+func regressions(ts []*trend, threshold float64) []regression {
+	var regs []regression
+	for _, t := range ts {
+		if len(t.points) < 2 {
+			continue
+		}
+		prev, last := &t.points[len(t.points)-2].res, &t.points[len(t.points)-1].res
+		for _, m := range trendMetrics {
+			old, ok1 := m.value(prev)
+			new, ok2 := m.value(last)
+			if !ok1 || !ok2 || old == 0 {
+				continue
+			}
+			if p := (new - old) / old * 100; p > threshold {
+				regs = append(regs, regression{t, m.unit, p})
+			}
+		}
+	}
+	sort.SliceStable(regs, func(i, j int) bool { return regs[i].percent > regs[j].percent })
+	return regs
+}
+
+// printTrend prints a table per trend with a line per run, giving the
+// change of each metric from the previous run, followed by the top largest
+// regressions of the latest runs.
+func printTrend(w io.Writer, ts []*trend, top int, threshold float64) {
+	for _, t := range ts {
+		first := t.points[0].res
+		if first.Procs > 1 {
+			fmt.Fprintf(w, "%s/%s (%s, GOMAXPROCS %d)\n", t.benchmark, t.router, first.Mode, first.Procs)
+		} else {
+			fmt.Fprintf(w, "%s/%s (%s)\n", t.benchmark, t.router, first.Mode)
+		}
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprint(tw, "  date\tcommit\tversion")
+		for _, m := range trendMetrics {
+			fmt.Fprintf(tw, "\t%s\t", m.unit)
+		}
+		fmt.Fprintln(tw)
+		for i, p := range t.points {
+			fmt.Fprintf(tw, "  %s\t%s\t%s", p.env.Date.Format("2006-01-02 15:04"),
+				orNone(p.env.Commit), orNone(p.env.Routers[t.router]))
+			for _, m := range trendMetrics {
+				v, ok := m.value(&t.points[i].res)
+				if !ok {
+					fmt.Fprint(tw, "\t-\t")
+					continue
+				}
+				change := ""
+				if i > 0 {
+					if old, ok := m.value(&t.points[i-1].res); ok && old != 0 {
+						change = fmt.Sprintf("%+.1f%%", (v-old)/old*100)
+					}
+				}
+				fmt.Fprintf(tw, "\t%s\t%s", formatValue(v), change)
+			}
+			fmt.Fprintln(tw)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+
+	regs := regressions(ts, threshold)
+	if len(regs) == 0 {
+		fmt.Fprintf(w, "No regressions above %g%% in the latest runs.\n", threshold)
+		return
+	}
+	if len(regs) > top {
+		regs = regs[:top]
+	}
+	fmt.Fprintf(w, "Largest regressions above %g%% in the latest runs:\n", threshold)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, reg := range regs {
+		prev, last := reg.t.points[len(reg.t.points)-2], reg.t.points[len(reg.t.points)-1]
+		fmt.Fprintf(tw, "  %s/%s\t%s\t%+.1f%%\t%s → %s\n", reg.t.benchmark, reg.t.router, reg.unit, reg.percent,
+			orNone(prev.env.Routers[reg.t.router]), orNone(last.env.Routers[reg.t.router]))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := func(day int, goji string, gojiNs, martiniNs float64, gojiMem uint64) *runResults {
+		return &runResults{
+			Env: environment{
+				Date:    time.Date(2017, 2, day, 10, 0, 0, 0, time.UTC),
+				Commit:  "0123456789ab",
+				Routers: map[string]string{"Goji": goji},
+			},
+			Results: []result{
+				{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Goji", Mode: "serial", Procs: 1,
+					NsPerOp: gojiNs, AllocsPerOp: 376, Memory: &memory{Bytes: gojiMem}},
+				{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Martini", Mode: "serial", Procs: 1,
+					NsPerOp: martiniNs, AllocsPerOp: 2410},
+				{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Goji", Mode: "parallel", Procs: 4,
+					NsPerOp: 1},
+			},
+		}
+	}
+	// saved out of order
+	for _, rr := range []*runResults{
+		run(20, "github.com/zenazn/goji@v1.0.1", 60000, 1100000, 120000),
+		run(14, "github.com/zenazn/goji@v1.0.0", 55000, 1130000, 98264),
+	} {
+		if _, err := saveRun(dir, rr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := saveRun(dir, run(14, "", 0, 0, 0)); err == nil {
+		t.Errorf("saved a run twice")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20170214T100000Z-0123456789ab.json")); err != nil {
+		t.Error(err)
+	}
+
+	runs, err := loadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Env.Date.Day() != 14 {
+		t.Fatalf("history not loaded oldest first")
+	}
+
+	cfg, err := newRunConfig("github", "", "serial", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := trends(runs, cfg)
+	if len(ts) != 2 || ts[0].router != "Goji" || ts[1].router != "Martini" || len(ts[0].points) != 2 {
+		t.Fatalf("trends %+v", ts)
+	}
+
+	regs := regressions(ts, 5)
+	if len(regs) != 2 || regs[0].unit != "mem-B" || regs[1].unit != "ns/op" || regs[1].t != ts[0] {
+		t.Errorf("regressions %+v, want mem-B and ns/op of Goji", regs)
+	}
+
+	var buf bytes.Buffer
+	printTrend(&buf, ts, 10, 5)
+	// tabwriter padding aside
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	out := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"GithubAll/Goji (serial)\n",
+		"2017-02-20 10:00 0123456789ab github.com/zenazn/goji@v1.0.1 60.0k +9.1% 376 +0.0% 120k +22.1%\n",
+		"2017-02-14 10:00 0123456789ab none 1.13M 2.41k -\n",
+		"Largest regressions above 5% in the latest runs:\n",
+		"GithubAll/Goji mem-B +22.1% github.com/zenazn/goji@v1.0.0 → github.com/zenazn/goji@v1.0.1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\nlacks %q", buf.String(), want)
+		}
+	}
+}
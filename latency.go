@@ -0,0 +1,240 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func latencyCommand(args []string) error {
+	flags := newFlagSet("latency", "")
+	var (
+		suiteName  = flags.String("suite", "GithubAPI", "the suite whose requests to sample")
+		routerList = flags.String("router", "", "comma-separated routers to sample, all if empty")
+		samples    = flags.Int("samples", 100000, "take `n` samples per router")
+		batch      = flags.Int("batch", 1, "time batches of `n` calls of a request, for routers faster than the clock")
+		histogram  = flags.Bool("histogram", true, "print a histogram per router")
+		sources    = newSuiteSources(flags)
+	)
+	flags.Parse(args)
+	if err := sources.register(); err != nil {
+		return err
+	}
+
+	cfg, err := newRunConfig(*suiteName, *routerList, serial.String(), 1)
+	if err != nil {
+		return err
+	}
+	if len(cfg.suites) != 1 {
+		return fmt.Errorf("latency samples a single suite")
+	}
+	if *samples < 1 || *batch < 1 {
+		return fmt.Errorf("invalid -samples %d or -batch %d", *samples, *batch)
+	}
+	s := cfg.suites[0]
+
+	var ls []*latencies
+	for _, r := range cfg.routers {
+		if !supports(r, s.routes) {
+			continue
+		}
+		lr := s.router(r)
+		if err := lr.verify(); err != nil {
+			fmt.Printf("%s\tFAILED routing verification\n", r.Name())
+			continue
+		}
+		ls = append(ls, sampleLatencies(lr, *samples, *batch))
+	}
+	printLatencies(os.Stdout, s, ls, *histogram)
+	return nil
+}
+
+// latencies are the durations of single ServeHTTP calls of a router, in ns.
+type latencies struct {
+	router string
+	batch  int
+
+	// ns is sorted
+	ns sample
+
+	// gcs is the number of garbage collections while sampling.
+	gcs uint32
+}
+
+// clockStart is the origin of nanotime.
+var clockStart = time.Now()
+
+// nanotime reads the monotonic clock, without the wall clock time.Now
+// reads as well.
+func nanotime() int64 {
+	return int64(time.Since(clockStart))
+}
+
+// clockOverhead returns the minimal duration measured between two
+// consecutive reads of nanotime, which is subtracted from the samples.
+func clockOverhead() int64 {
+	min := int64(math.MaxInt64)
+	for i := 0; i < 1000; i++ {
+		t0 := nanotime()
+		if d := nanotime() - t0; d < min {
+			min = d
+		}
+	}
+	return min
+}
+
+// sampleLatencies takes n samples of requests of lr, cycling through the
+// requests of its route set. A sample times a batch of calls with the same
+// request and is their mean duration, less the clock overhead. The samples
+// are stored in memory allocated beforehand, so sampling allocates nothing
+// beyond what the router does.
+func sampleLatencies(lr *loadedRouter, n, batch int) *latencies {
+	w := new(mockResponseWriter)
+	reqs := make([]*http.Request, len(lr.requests))
+	queries := make([]string, len(lr.requests))
+	for i, req := range lr.requests {
+		reqs[i], _ = http.NewRequest(req.method, req.path, nil)
+		reqs[i].RequestURI = reqs[i].URL.RequestURI()
+		queries[i] = reqs[i].URL.RawQuery
+	}
+	durations := make([]int64, n)
+	overhead := clockOverhead()
+
+	// warm up caches and the router's pools
+	for _, r := range reqs {
+		lr.ServeHTTP(w, r)
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	for i := range durations {
+		r, q := reqs[i%len(reqs)], queries[i%len(reqs)]
+		t0 := nanotime()
+		for j := 0; j < batch; j++ {
+			r.URL.RawQuery = q
+			lr.ServeHTTP(w, r)
+		}
+		durations[i] = nanotime() - t0 - overhead
+	}
+
+	runtime.ReadMemStats(&after)
+	l := &latencies{router: lr.router.Name(), batch: batch, gcs: after.NumGC - before.NumGC}
+	l.ns = make(sample, n)
+	for i, d := range durations {
+		if d < 0 {
+			d = 0
+		}
+		l.ns[i] = float64(d) / float64(batch)
+	}
+	sort.Float64s(l.ns)
+	return l
+}
+
+// latencyPercentiles are the percentiles printed, max is the 100th.
+var latencyPercentiles = []float64{50, 90, 99, 99.9, 100}
+
+// latencyBucket is a bucket of a latency histogram, of durations in
+// [lo, hi).
+type latencyBucket struct {
+	lo, hi float64
+	count  int
+}
+
+// histogram returns the histogram of l with buckets bounded by 1, 2 and 5
+// times the powers of 10, from the bucket of the fastest to the one of the
+// slowest sample.
+func (l *latencies) histogram() []latencyBucket {
+	if len(l.ns) == 0 {
+		return nil
+	}
+	bound := func(i int) float64 {
+		return []float64{1, 2, 5}[i%3] * math.Pow(10, float64(i/3))
+	}
+	// the index of the bucket of v
+	index := func(v float64) int {
+		i := 0
+		for bound(i+1) <= v {
+			i++
+		}
+		return i
+	}
+
+	first, last := index(l.ns[0]), index(l.ns[len(l.ns)-1])
+	buckets := make([]latencyBucket, last-first+1)
+	for i := range buckets {
+		buckets[i].lo, buckets[i].hi = bound(first+i), bound(first+i+1)
+	}
+	// samples below 1 ns, left by subtracting the clock overhead
+	buckets[0].lo = math.Min(buckets[0].lo, l.ns[0])
+	b := 0
+	for _, v := range l.ns {
+		for v >= buckets[b].hi {
+			b++
+		}
+		buckets[b].count++
+	}
+	return buckets
+}
+
+// printLatencies prints the percentiles of each router and, if histogram
+// is set, their histograms.
+func printLatencies(w io.Writer, s *suite, ls []*latencies, histogram bool) {
+	if len(ls) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s, ns per request of %d samples", s.name, len(ls[0].ns))
+	if ls[0].batch > 1 {
+		fmt.Fprintf(w, " of batches of %d", ls[0].batch)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "Router\t")
+	for _, p := range latencyPercentiles {
+		if p == 100 {
+			fmt.Fprint(tw, "max\t")
+		} else {
+			fmt.Fprintf(tw, "p%g\t", p)
+		}
+	}
+	fmt.Fprintln(tw, "mean\tGCs\t")
+	for _, l := range ls {
+		fmt.Fprintf(tw, "%s\t", l.router)
+		var sum float64
+		for _, v := range l.ns {
+			sum += v
+		}
+		for _, p := range latencyPercentiles {
+			fmt.Fprintf(tw, "%.0f\t", l.ns.percentile(p))
+		}
+		fmt.Fprintf(tw, "%.0f\t%d\t\n", sum/float64(len(l.ns)), l.gcs)
+	}
+	tw.Flush()
+
+	if !histogram {
+		return
+	}
+	const width = 50
+	for _, l := range ls {
+		fmt.Fprintf(w, "\n%s\n", l.router)
+		tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+		for _, b := range l.histogram() {
+			share := float64(b.count) / float64(len(l.ns))
+			bar := strings.Repeat("#", int(math.Ceil(share*width)))
+			fmt.Fprintf(tw, "%s\t- %s ns\t%d\t%5.1f%%\t %s\n", formatValue(b.lo), formatValue(b.hi), b.count, share*100, bar)
+		}
+		tw.Flush()
+	}
+}
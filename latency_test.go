@@ -0,0 +1,51 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	l := &latencies{router: "Goji", batch: 1, ns: sample{120, 150, 180, 250, 900, 4000}}
+	want := []latencyBucket{
+		{100, 200, 3}, {200, 500, 1}, {500, 1000, 1}, {1000, 2000, 0}, {2000, 5000, 1},
+	}
+	if got := l.histogram(); !reflect.DeepEqual(got, want) {
+		t.Errorf("histogram %v, want %v", got, want)
+	}
+	// samples of 0 ns, left by subtracting the clock overhead
+	zero := &latencies{ns: sample{0, 1.5}}
+	if got := zero.histogram(); !reflect.DeepEqual(got, []latencyBucket{{0, 2, 2}}) {
+		t.Errorf("histogram %v of samples of 0 ns", got)
+	}
+
+	var buf bytes.Buffer
+	printLatencies(&buf, githubSuite, []*latencies{l}, true)
+	out := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{
+		"Router p50 p90 p99 p99.9 max mean GCs Goji 180 4000 4000 4000 4000 933 0",
+		"100 - 200 ns 3 50.0% #########################",
+		"1.00k - 2.00k ns 0 0.0%",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\nlacks %q", buf.String(), want)
+		}
+	}
+}
+
+func TestSampleLatencies(t *testing.T) {
+	lr := staticSuite.router(findRouter("HttpServeMux"))
+	l := sampleLatencies(lr, 1000, 2)
+	if len(l.ns) != 1000 || l.router != "HttpServeMux" {
+		t.Fatalf("%d samples of %s", len(l.ns), l.router)
+	}
+	if l.ns[0] > l.ns[len(l.ns)-1] || l.ns[len(l.ns)-1] <= 0 {
+		t.Errorf("samples not sorted or all 0")
+	}
+}
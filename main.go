@@ -0,0 +1,418 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+const usage = `Usage: go-http-routing-benchmark [command] [flags] [args]
+
+Commands:
+%s
+Without a command, the benchmarks are run. They also run with:
+
+	go test -bench=. -timeout=20m
+
+Run go-http-routing-benchmark command -h for the flags of a command.
+`
+
+// commands are the subcommands of the binary.
+var commands = []struct {
+	name, summary string
+	run           func(args []string) error
+}{
+	{"run", "run the benchmarks and print the results (default)", runCommand},
+	{"convert", "convert saved go test -bench output to another format", convertCommand},
+	{"readme", "update the result sections of the README", readmeCommand},
+	{"compare", "compare two runs with repeated results statistically", compareCommand},
+	{"chart", "render SVG charts of results", chartCommand},
+	{"report", "write a self-contained HTML report of results", reportCommand},
+	{"score", "score the routers across the suites", scoreCommand},
+	{"breakdown", "time each route of a suite and break the times down by route shape", breakdownCommand},
+	{"latency", "sample the latency distribution of single requests", latencyCommand},
+	{"scale", "measure how the routers scale with the number of routes", scaleCommand},
+	{"export", "write the routes of a suite as a route file", exportCommand},
+	{"save", "save results to the history", saveCommand},
+	{"trend", "show the trends of the results in the history", trendCommand},
+}
+
+func main() {
+	args := os.Args[1:]
+	run := commands[0].run
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		run = nil
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				run = cmd.run
+			}
+		}
+		if run == nil {
+			printUsage()
+			os.Exit(2)
+		}
+		args = args[1:]
+	}
+	if len(os.Args) == 2 && (os.Args[1] == "-h" || os.Args[1] == "-help") {
+		printUsage()
+		return
+	}
+
+	if err := run(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	var list strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&list, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, usage, list.String())
+}
+
+// newFlagSet returns the flag set of the named command.
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: go-http-routing-benchmark %s [flags] %s\n\nFlags:\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// formats are the output formats of writeResults.
+var formats = []string{"table", "bench", "json", "csv"}
+
+// writeResults writes rr in the named format. The table and bench formats
+// start with the environment as configuration lines.
+func writeResults(w io.Writer, rr *runResults, format string) error {
+	switch format {
+	case "table":
+		rr.Env.printConfig(w)
+		fmt.Fprintln(w)
+		printTable(w, medianResults(rr.Results))
+	case "bench":
+		rr.Env.printConfig(w)
+		printBench(w, rr.Results)
+	case "json":
+		return writeJSON(w, rr)
+	case "csv":
+		return writeCSV(w, rr)
+	default:
+		return fmt.Errorf("unknown format %q, want one of %s", format, strings.Join(formats, ", "))
+	}
+	return nil
+}
+
+func runCommand(args []string) error {
+	flags := newFlagSet("run", "")
+	var (
+		suiteList  = flags.String("suite", "", "comma-separated suites to run, all if empty")
+		routerList = flags.String("router", "", "comma-separated routers to benchmark, all if empty")
+		modeList   = flags.String("mode", "serial", "comma-separated modes: "+strings.Join(modeNames, ", "))
+		count      = flags.Int("count", 1, "run each benchmark `n` times")
+		benchtime  = flags.String("benchtime", "1s", "run each benchmark for `t`, a duration or Nx iterations")
+		format     = flags.String("format", "table", "output format: "+strings.Join(formats, ", "))
+		list       = flags.Bool("list", false, "list the suites and routers and exit")
+		history    = flags.String("history", "", "also save the results to the history `dir`")
+		sources    = newSuiteSources(flags)
+	)
+	flags.Parse(args)
+	if err := sources.register(); err != nil {
+		return err
+	}
+
+	if *list {
+		printList(os.Stdout)
+		return nil
+	}
+
+	cfg, err := newRunConfig(*suiteList, *routerList, *modeList, *count)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(ioutil.Discard, new(runResults), *format); err != nil {
+		return err
+	}
+	testing.Init()
+	if err := flag.Set("test.benchtime", *benchtime); err != nil {
+		return err
+	}
+
+	rr := &runResults{Env: captureEnv(), Results: cfg.run()}
+	if *history != "" {
+		if _, err := saveRun(*history, rr); err != nil {
+			return err
+		}
+	}
+	return writeResults(os.Stdout, rr, *format)
+}
+
+func convertCommand(args []string) error {
+	flags := newFlagSet("convert", "[file]")
+	format := flags.String("format", "json", "output format: "+strings.Join(formats, ", "))
+	flags.Parse(args)
+
+	in := os.Stdin
+	if flags.NArg() > 0 {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	rr, err := readResults(in)
+	if err != nil {
+		return err
+	}
+	return writeResults(os.Stdout, rr, *format)
+}
+
+// runConfig selects the benchmarks the runner runs.
+type runConfig struct {
+	suites  []*suite
+	routers []Router
+	modes   []mode
+	count   int
+}
+
+// newRunConfig parses comma-separated lists of suite, router and mode names.
+// Names are case-insensitive, suites may be named without the API suffix.
+// An empty suite or router list selects all of them.
+func newRunConfig(suiteList, routerList, modeList string, count int) (*runConfig, error) {
+	cfg := &runConfig{suites: suites, routers: routers, count: count}
+	if count < 1 {
+		return nil, fmt.Errorf("invalid count %d", count)
+	}
+
+	if suiteList != "" {
+		cfg.suites = nil
+		for _, name := range splitList(suiteList) {
+			s := findSuite(name)
+			if s == nil {
+				return nil, fmt.Errorf("unknown suite %q, see -list", name)
+			}
+			cfg.suites = append(cfg.suites, s)
+		}
+	}
+	if routerList != "" {
+		cfg.routers = nil
+		for _, name := range splitList(routerList) {
+			r := findRouter(name)
+			if r == nil {
+				return nil, fmt.Errorf("unknown router %q, see -list", name)
+			}
+			cfg.routers = append(cfg.routers, r)
+		}
+	}
+	for _, name := range splitList(modeList) {
+		m, err := parseMode(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg.modes = append(cfg.modes, m)
+	}
+	return cfg, nil
+}
+
+func splitList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func findSuite(name string) *suite {
+	for _, s := range suites {
+		if strings.EqualFold(s.name, name) || strings.EqualFold(strings.TrimSuffix(s.name, "API"), name) {
+			return s
+		}
+	}
+	return nil
+}
+
+func findRouter(name string) Router {
+	for _, r := range routers {
+		if strings.EqualFold(r.Name(), name) {
+			return r
+		}
+	}
+	return nil
+}
+
+// run runs the selected benchmarks through testing.Benchmark, in the order
+// of the suites, their benchmarks, the modes and the routers.
+func (cfg *runConfig) run() []result {
+	var results []result
+	for _, s := range cfg.suites {
+		for _, bm := range s.benchmarks {
+			for _, m := range cfg.modes {
+				for _, r := range cfg.routers {
+					if !supports(r, s.routes) {
+						continue
+					}
+					results = append(results, cfg.runOne(s, bm, r, m)...)
+				}
+			}
+		}
+	}
+	return results
+}
+
+// runOne runs bm on r count times. A router failing the routing
+// verification gets a single result with the error. The parallel mode runs
+// with a thread per CPU, the others with one.
+func (cfg *runConfig) runOne(s *suite, bm benchmark, r Router, m mode) []result {
+	if m == parallel {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(runtime.NumCPU()))
+	}
+	res := result{
+		Suite:     s.name,
+		Benchmark: bm.name,
+		Router:    r.Name(),
+		Mode:      m.String(),
+		Procs:     runtime.GOMAXPROCS(0),
+	}
+	lr := s.router(r)
+	if err := lr.verify(); err != nil {
+		res.Error = "FAILED routing verification"
+		return []result{res}
+	}
+	res.Routes = len(lr.routes)
+
+	results := make([]result, cfg.count)
+	for i := range results {
+		br := testing.Benchmark(func(b *testing.B) {
+			benchLoaded(b, lr, bm, m)
+		})
+		results[i] = res
+		results[i].N = br.N
+		results[i].setMetric("ns/op", float64(br.T.Nanoseconds())/float64(br.N))
+		results[i].setMetric("B/op", float64(br.AllocedBytesPerOp()))
+		results[i].setMetric("allocs/op", float64(br.AllocsPerOp()))
+		for unit, v := range br.Extra {
+			results[i].setMetric(unit, v)
+		}
+	}
+	return results
+}
+
+// medianResults reduces repeated runs of a benchmark to the one with the
+// median time per op, keeping the order of the first runs.
+func medianResults(results []result) []result {
+	var keys []string
+	runs := make(map[string][]result)
+	for _, res := range results {
+		k := res.key()
+		if _, ok := runs[k]; !ok {
+			keys = append(keys, k)
+		}
+		runs[k] = append(runs[k], res)
+	}
+
+	medians := make([]result, len(keys))
+	for i, k := range keys {
+		rs := runs[k]
+		sort.SliceStable(rs, func(i, j int) bool { return rs[i].NsPerOp < rs[j].NsPerOp })
+		medians[i] = rs[len(rs)/2]
+	}
+	return medians
+}
+
+// printBench prints results in the format of go test -bench.
+func printBench(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, res := range results {
+		if res.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\n", res.benchName(), res.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d", res.benchName(), res.N)
+		for _, m := range res.metrics() {
+			fmt.Fprintf(tw, "\t%s %s", strconv.FormatFloat(m.value, 'f', -1, 64), m.unit)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// printTable prints a table per benchmark, mode and GOMAXPROCS comparing the
+// routers, with their time per op relative to the fastest one.
+func printTable(w io.Writer, results []result) {
+	var keys []string
+	groups := make(map[string][]result)
+	for _, res := range results {
+		k := fmt.Sprintf("%s/%s/%d", res.Benchmark, res.Mode, res.Procs)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], res)
+	}
+
+	for _, k := range keys {
+		group := groups[k]
+		var fastest float64
+		for _, res := range group {
+			if res.Error == "" && (fastest == 0 || res.NsPerOp < fastest) {
+				fastest = res.NsPerOp
+			}
+		}
+
+		first := group[0]
+		if first.Procs > 1 {
+			fmt.Fprintf(w, "%s (%s, GOMAXPROCS %d)\n", first.Benchmark, first.Mode, first.Procs)
+		} else {
+			fmt.Fprintf(w, "%s (%s)\n", first.Benchmark, first.Mode)
+		}
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "Router\tns/op\tns/req\tB/op\tallocs/op\t%routes\trelative\t")
+		var failed []string
+		for _, res := range group {
+			if res.Error != "" {
+				failed = append(failed, res.Router)
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t%.0f\t%.1f\t%d\t%d\t%.1f\t%.2fx\t\n", res.Router, res.NsPerOp, res.NsPerRequest,
+				res.BytesPerOp, res.AllocsPerOp, res.Coverage, res.NsPerOp/fastest)
+		}
+		tw.Flush()
+		if len(failed) > 0 {
+			fmt.Fprintf(w, "FAILED routing verification: %s\n", strings.Join(failed, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printList prints the suites with their benchmarks and the routers.
+func printList(w io.Writer) {
+	fmt.Fprintln(w, "Suites:")
+	for _, s := range suites {
+		names := make([]string, len(s.benchmarks))
+		for i, bm := range s.benchmarks {
+			names[i] = bm.name
+		}
+		fmt.Fprintf(w, "  %-12s %3d routes: %s\n", s.name, len(s.routes), strings.Join(names, ", "))
+	}
+	fmt.Fprintln(w, "Routers:")
+	for _, r := range routers {
+		fmt.Fprintf(w, "  %-12s %s\n", r.Name(), r.Capabilities())
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestNewRunConfig(t *testing.T) {
+	cfg, err := newRunConfig("github, gplusapi", "goji,GorillaMux", "serial,e2e", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.suites) != 2 || cfg.suites[0] != githubSuite || cfg.suites[1] != gplusSuite {
+		t.Errorf("suites not selected by name")
+	}
+	if len(cfg.routers) != 2 || cfg.routers[0].Name() != "Goji" || cfg.routers[1].Name() != "GorillaMux" {
+		t.Errorf("routers not selected by name")
+	}
+	if len(cfg.modes) != 2 || cfg.modes[0] != serial || cfg.modes[1] != endToEnd {
+		t.Errorf("modes %v, want [serial e2e]", cfg.modes)
+	}
+
+	cfg, err = newRunConfig("", "", "parallel", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.suites) != len(suites) || len(cfg.routers) != len(routers) {
+		t.Errorf("empty lists do not select everything")
+	}
+
+	for _, args := range [][3]string{
+		{"nosuchsuite", "", "serial"},
+		{"", "nosuchrouter", "serial"},
+		{"", "", "nosuchmode"},
+	} {
+		if _, err := newRunConfig(args[0], args[1], args[2], 1); err == nil {
+			t.Errorf("newRunConfig%q: no error", args)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// memStats is the memory footprint of loading a routing structure.
+type memStats struct {
+	// retained is the number of heap bytes still in use once loading is
+	// done and the garbage collected, i.e. the size of the structure.
+	retained uint64
+
+	// allocated and allocs are the number of heap bytes and objects
+	// allocated while loading, garbage included.
+	allocated uint64
+	allocs    uint64
+
+	// gcs is the number of GC cycles loading triggered.
+	gcs uint32
+
+	// routes is the number of routes loaded.
+	routes int
+}
+
+// measureMem measures the memory footprint of loading routes by calling
+// load. Nothing else may allocate meanwhile, so it must not run in parallel
+// with other goroutines.
+func measureMem(routes int, load func()) memStats {
+	m := new(runtime.MemStats)
+
+	// before; the second cycle frees what the first one moved from
+	// sync.Pools to their victim caches
+	runtime.GC()
+	runtime.GC()
+	runtime.ReadMemStats(m)
+	heap, total, mallocs, gcs := m.HeapAlloc, m.TotalAlloc, m.Mallocs, m.NumGC
+
+	load()
+
+	// after, reading the counters before our own GC cycle adds to them
+	runtime.ReadMemStats(m)
+	ms := memStats{
+		allocated: m.TotalAlloc - total,
+		allocs:    m.Mallocs - mallocs,
+		gcs:       m.NumGC - gcs,
+		routes:    routes,
+	}
+	runtime.GC()
+	runtime.ReadMemStats(m)
+	if m.HeapAlloc > heap {
+		ms.retained = m.HeapAlloc - heap
+	}
+	return ms
+}
+
+// bytesPerRoute returns the retained bytes per loaded route.
+func (ms memStats) bytesPerRoute() float64 {
+	if ms.routes == 0 {
+		return 0
+	}
+	return float64(ms.retained) / float64(ms.routes)
+}
+
+func (ms memStats) String() string {
+	return fmt.Sprintf("%d Bytes (%.0f Bytes/route), allocated %d Bytes in %d allocs, %d GCs",
+		ms.retained, ms.bytesPerRoute(), ms.allocated, ms.allocs, ms.gcs)
+}
+
+// report adds ms to the results of b as custom metrics.
+func (ms memStats) report(b *testing.B) {
+	b.ReportMetric(float64(ms.retained), "mem-B")
+	b.ReportMetric(ms.bytesPerRoute(), "mem-B/route")
+	b.ReportMetric(float64(ms.allocated), "load-B")
+	b.ReportMetric(float64(ms.allocs), "load-allocs")
+	b.ReportMetric(float64(ms.gcs), "load-GCs")
+}
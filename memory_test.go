@@ -0,0 +1,38 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+// sink makes values escape to the heap.
+var sink []byte
+
+func TestMeasureMem(t *testing.T) {
+	var kept [][]byte
+	ms := measureMem(100, func() {
+		for i := 0; i < 100; i++ {
+			kept = append(kept, make([]byte, 1024))
+			sink = make([]byte, 1024) // garbage
+		}
+	})
+
+	if ms.retained < 100*1024 || ms.retained >= 200*1024 {
+		t.Errorf("retained %d Bytes, want about %d", ms.retained, 100*1024)
+	}
+	if ms.allocated < 200*1024 {
+		t.Errorf("allocated %d Bytes, want at least %d", ms.allocated, 200*1024)
+	}
+	if ms.allocs < 200 {
+		t.Errorf("%d allocs, want at least 200", ms.allocs)
+	}
+	if bpr := ms.bytesPerRoute(); bpr < 1024 {
+		t.Errorf("%.0f Bytes/route, want at least 1024", bpr)
+	}
+	if len(kept) != 100 {
+		t.Fatal("kept slices lost")
+	}
+}
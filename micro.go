@@ -0,0 +1,44 @@
+// Copyright 2013 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+func paramWriteHandler(ps Params) string {
+	return ps.Get("name")
+}
+
+// Micro Benchmarks
+
+// Route with Param (no write)
+var paramSuite = registerSuite(&suite{
+	name:       "Param",
+	routes:     []route{{"GET", "/user/:name"}},
+	micro:      true,
+	benchmarks: []benchmark{{"Param", "/user/gordon"}},
+})
+
+// Route with 5 Params (no write)
+var param5Suite = registerSuite(&suite{
+	name:       "Param5",
+	routes:     []route{{"GET", "/:a/:b/:c/:d/:e"}},
+	micro:      true,
+	benchmarks: []benchmark{{"Param5", "/test/test/test/test/test"}},
+})
+
+// Route with 20 Params (no write)
+var param20Suite = registerSuite(&suite{
+	name:       "Param20",
+	routes:     []route{{"GET", "/:a/:b/:c/:d/:e/:f/:g/:h/:i/:j/:k/:l/:m/:n/:o/:p/:q/:r/:s/:t"}},
+	micro:      true,
+	benchmarks: []benchmark{{"Param20", "/a/b/c/d/e/f/g/h/i/j/k/l/m/n/o/p/q/r/s/t"}},
+})
+
+// Route with Param and write
+var paramWriteSuite = registerSuite(&suite{
+	name:       "ParamWrite",
+	routes:     []route{{"GET", "/user/:name"}},
+	handler:    paramWriteHandler,
+	micro:      true,
+	benchmarks: []benchmark{{"ParamWrite", "/user/gordon"}},
+})
@@ -0,0 +1,116 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var procsFlag = flag.String("procs", "1,2,4,8", "comma-separated GOMAXPROCS values BenchmarkScaling runs each router with")
+
+// parseProcs parses a comma-separated list of GOMAXPROCS values.
+func parseProcs(s string) ([]int, error) {
+	var procs []int
+	for _, f := range strings.Split(s, ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid GOMAXPROCS value %q in -procs", f)
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// BenchmarkParallel runs every benchmark of every suite with b.RunParallel,
+// by as many goroutines as GOMAXPROCS. Like the parallel mode of the runner
+// it sets GOMAXPROCS to the number of CPU threads, unless -cpu sets it.
+func BenchmarkParallel(b *testing.B) {
+	if f := flag.Lookup("test.cpu"); f == nil || f.Value.String() == "" {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(runtime.NumCPU()))
+	}
+	for _, s := range suites {
+		for _, bm := range s.benchmarks {
+			b.Run(bm.name, func(b *testing.B) {
+				s.benchRouters(b, bm, parallel)
+			})
+		}
+	}
+}
+
+// BenchmarkEndToEnd runs every benchmark of every suite over HTTP, with the
+// router serving a loopback server. Beside routing it measures what net/http
+// costs, which puts the differences between the routers into proportion.
+func BenchmarkEndToEnd(b *testing.B) {
+	for _, s := range suites {
+		for _, bm := range s.benchmarks {
+			b.Run(bm.name, func(b *testing.B) {
+				s.benchRouters(b, bm, endToEnd)
+			})
+		}
+	}
+}
+
+// BenchmarkScaling runs the benchmarks requesting all routes of a suite in
+// parallel with each of the -procs GOMAXPROCS values. Each run reports the
+// throughput in requests per second and its speedup over the first value;
+// after all of them the scaling curve of the router is printed.
+func BenchmarkScaling(b *testing.B) {
+	procs, err := parseProcs(*procsFlag)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, s := range suites {
+		for _, bm := range s.benchmarks {
+			if bm.path != "" {
+				continue
+			}
+			b.Run(bm.name, func(b *testing.B) {
+				s.eachRouter(func(r Router) {
+					b.Run(r.Name(), func(b *testing.B) {
+						scale(b, s, r, bm, procs)
+					})
+				})
+			})
+		}
+	}
+}
+
+// scale runs bm on r with each of procs as GOMAXPROCS and prints the curve
+// of the speedups.
+func scale(b *testing.B, s *suite, r Router, bm benchmark, procs []int) {
+	var base float64
+	var curve []string
+	for _, p := range procs {
+		var throughput float64
+		b.Run(fmt.Sprintf("procs=%d", p), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(p))
+
+			lr := s.router(r)
+			benchLoaded(b, lr, bm, parallel)
+			throughput = float64(b.N*len(lr.requests)) / b.Elapsed().Seconds()
+			b.ReportMetric(throughput, "req/s")
+			if base > 0 {
+				b.ReportMetric(throughput/base, "speedup")
+			}
+		})
+
+		// skipped or filtered out by -bench
+		if throughput == 0 {
+			continue
+		}
+		if base == 0 {
+			base = throughput
+		}
+		curve = append(curve, fmt.Sprintf("%d:%.2fx", p, throughput/base))
+	}
+	if len(curve) > 0 {
+		fmt.Printf("%s\tscaling %s\n", b.Name(), strings.Join(curve, " "))
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// openAPIMethods are the operations of an OpenAPI path item.
+var openAPIMethods = map[string]string{
+	"get":     "GET",
+	"put":     "PUT",
+	"post":    "POST",
+	"delete":  "DELETE",
+	"options": "OPTIONS",
+	"head":    "HEAD",
+	"patch":   "PATCH",
+	"trace":   "TRACE",
+}
+
+// openAPIDoc is the route set of an OpenAPI document.
+type openAPIDoc struct {
+	title  string
+	routes []route
+
+	// skipped are the operations the canonical syntax can not represent,
+	// with the reason.
+	skipped []string
+}
+
+// parseOpenAPI reads the routes of an OpenAPI 2 (Swagger) or 3 document in
+// JSON, in the order of the document. The {param} templates of the paths
+// become named parameters, the base path of the document or of its first
+// server is prepended. Path item references are not followed.
+func parseOpenAPI(data []byte) (*openAPIDoc, error) {
+	var doc struct {
+		Swagger  string
+		OpenAPI  string
+		BasePath string
+		Servers  []struct{ URL string }
+		Info     struct{ Title string }
+		Paths    json.RawMessage
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	d := &openAPIDoc{title: doc.Info.Title}
+	var base string
+	switch {
+	case strings.HasPrefix(doc.Swagger, "2."):
+		base = doc.BasePath
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		if len(doc.Servers) > 0 {
+			u, err := url.Parse(doc.Servers[0].URL)
+			if err != nil || strings.Contains(u.Path, "{") {
+				d.skipped = append(d.skipped, fmt.Sprintf("base path of server %s: not a plain path", doc.Servers[0].URL))
+			} else {
+				base = u.Path
+			}
+		}
+	default:
+		return nil, fmt.Errorf("not an OpenAPI 2 or 3 document")
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	paths, err := objectKeys(doc.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("paths: %v", err)
+	}
+	seen := make(map[string]string)
+	for _, p := range paths {
+		ops, err := objectKeys(p.value)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %v", p.key, err)
+		}
+		path, err := canonicalPath(base + p.key)
+		for _, op := range ops {
+			if op.key == "$ref" {
+				d.skipped = append(d.skipped, fmt.Sprintf("%s: path item reference", p.key))
+				continue
+			}
+			method, ok := openAPIMethods[op.key]
+			if !ok {
+				continue
+			}
+			switch r := (route{method, path}); {
+			case err != nil:
+				d.skipped = append(d.skipped, fmt.Sprintf("%s %s: %v", method, p.key, err))
+			case seen[r.key()] != "":
+				d.skipped = append(d.skipped, fmt.Sprintf("%s %s: same route as %s", method, p.key, seen[r.key()]))
+			default:
+				seen[r.key()] = p.key
+				d.routes = append(d.routes, r)
+			}
+		}
+	}
+	if len(d.routes) == 0 {
+		return nil, fmt.Errorf("no routes")
+	}
+	return d, nil
+}
+
+// canonicalPath converts an OpenAPI path template to the canonical syntax.
+// A template has to make up a whole segment, a static segment must not start
+// with a character of the canonical syntax.
+func canonicalPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("path does not start with /")
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") && strings.Count(part, "{") == 1:
+			name := part[1 : len(part)-1]
+			if name == "" || strings.ContainsAny(name, "}") {
+				return "", fmt.Errorf("invalid parameter %s", part)
+			}
+			parts[i] = ":" + name
+		case strings.ContainsAny(part, "{}"):
+			return "", fmt.Errorf("parameter in a part of segment %s", part)
+		case strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*"):
+			return "", fmt.Errorf("static segment %s reads as a parameter", part)
+		}
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// objectKey is a member of a JSON object.
+type objectKey struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectKeys returns the members of the JSON object data in their order,
+// which decoding into a map loses.
+func objectKeys(data json.RawMessage) ([]objectKey, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("not an object")
+	}
+	var keys []objectKey
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		keys = append(keys, objectKey{t.(string), value})
+	}
+	return keys, nil
+}
+
+// mustParseOpenAPI parses the OpenAPI document of a built-in suite.
+func mustParseOpenAPI(data string) []route {
+	d, err := parseOpenAPI([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	return d.routes
+}
+
+// registerOpenAPI registers a suite for each of the comma-separated OpenAPI
+// documents in files, printing the operations left out. The suite is named
+// after the title of the document, or its file name if untitled.
+func registerOpenAPI(files string) error {
+	for _, file := range splitList(files) {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		d, err := parseOpenAPI(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		title := d.title
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		s, err := newRouteSuite(title, d.routes, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		for _, op := range d.skipped {
+			fmt.Fprintf(os.Stderr, "#%s skipped %s\n", s.name, op)
+		}
+		registerSuite(s)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOpenAPI(t *testing.T) {
+	d, err := parseOpenAPI([]byte(`{
+		"openapi": "3.0.1",
+		"info": {"title": "Zoo"},
+		"servers": [{"url": "https://zoo.example.com/api/"}],
+		"paths": {
+			"/animals": {"summary": "all", "get": {}, "post": {}, "x-internal": {}},
+			"/animals/{animalId}": {"parameters": [], "delete": {}, "get": {}},
+			"/animals/{id}": {"get": {}, "patch": {}},
+			"/animals/{animalId}/photo.{format}": {"get": {}},
+			"/keepers": {"$ref": "#/components/pathItems/keepers"},
+			"/:admin": {"get": {}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.title != "Zoo" {
+		t.Errorf("title %q", d.title)
+	}
+	want := []route{
+		{"GET", "/api/animals"},
+		{"POST", "/api/animals"},
+		{"DELETE", "/api/animals/:animalId"},
+		{"GET", "/api/animals/:animalId"},
+		{"PATCH", "/api/animals/:id"},
+	}
+	if !reflect.DeepEqual(d.routes, want) {
+		t.Errorf("routes\n%v, want\n%v", d.routes, want)
+	}
+	wantSkipped := []string{
+		"GET /animals/{id}: same route as /animals/{animalId}",
+		"GET /animals/{animalId}/photo.{format}: parameter in a part of segment photo.{format}",
+		"/keepers: path item reference",
+		"GET /:admin: static segment :admin reads as a parameter",
+	}
+	if !reflect.DeepEqual(d.skipped, wantSkipped) {
+		t.Errorf("skipped\n%q, want\n%q", d.skipped, wantSkipped)
+	}
+}
+
+func TestParseOpenAPIErrors(t *testing.T) {
+	for _, doc := range []string{
+		`{"info": {"title": "none"}, "paths": {"/a": {"get": {}}}}`,
+		`{"swagger": "2.0", "paths": []}`,
+		`{"swagger": "2.0", "paths": {"/a": {"x-get": {}}}}`,
+		`{"swagger": "2.0"`,
+	} {
+		if _, err := parseOpenAPI([]byte(doc)); err == nil {
+			t.Errorf("no error for %s", doc)
+		}
+	}
+}
+
+func TestOpenAPIMethods(t *testing.T) {
+	doc, err := parseOpenAPI([]byte(`{"swagger": "2.0", "paths": {
+		"/pets": {"get": {}, "head": {}, "options": {}, "post": {}},
+		"/pets/{id}": {"get": {}, "head": {}, "options": {}, "trace": {}, "delete": {}}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLoadRouters(t, doc.routes, genRequests(doc.routes, requestSeed))
+}
+
+func TestPetstoreAPI(t *testing.T) {
+	if len(petstoreAPI) != 20 {
+		t.Errorf("%d Petstore routes, want 20", len(petstoreAPI))
+	}
+	if petstoreAPI[0] != (route{"POST", "/v2/pet/:petId/uploadImage"}) {
+		t.Errorf("first route %v", petstoreAPI[0])
+	}
+}
+
+func TestRegisterOpenAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "shop.json")
+	doc := `{"swagger": "2.0", "paths": {
+		"/items": {"post": {}},
+		"/items/{id}": {"get": {}},
+		"/status": {"get": {}}
+	}}`
+	if err := ioutil.WriteFile(file, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(suites)
+	defer func() { suites = suites[:n] }()
+	if err := registerOpenAPI(file); err != nil {
+		t.Fatal(err)
+	}
+	s := findSuite("shop")
+	if s == nil || s.name != "ShopAPI" {
+		t.Fatalf("no suite ShopAPI in %d suites", len(suites))
+	}
+	want := []benchmark{
+		{"ShopStatic", "/status"},
+		{"ShopParam", s.requests[1].path},
+		{"ShopAll", ""},
+	}
+	if !reflect.DeepEqual(s.benchmarks, want) {
+		t.Errorf("benchmarks %v, want %v", s.benchmarks, want)
+	}
+	if err := registerOpenAPI(file); err == nil {
+		t.Error("registered the suite twice")
+	}
+}
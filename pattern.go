@@ -0,0 +1,231 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pattern is a route path in the canonical syntax, parsed into its segments.
+// The route sets are written in the canonical syntax and translated to the
+// dialect of each router.
+type pattern []segment
+
+// parsePattern parses a path in the canonical syntax: /user/:name for a
+// named parameter, /src/*path for a catch-all, which must be the last
+// segment. A path may not repeat a parameter name.
+func parsePattern(path string) (pattern, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path %q does not start with /", path)
+	}
+	p := pattern(route{path: path}.segments())
+	names := make(map[string]bool)
+	for i, seg := range p {
+		if seg.kind == staticSegment {
+			continue
+		}
+		if seg.name == "" {
+			return nil, fmt.Errorf("unnamed parameter in %s", path)
+		}
+		if names[seg.name] {
+			return nil, fmt.Errorf("parameter %s repeated in %s", seg.name, path)
+		}
+		names[seg.name] = true
+		if seg.kind == catchAllSegment && i != len(p)-1 {
+			return nil, fmt.Errorf("catch-all %s is not the last segment of %s", seg.name, path)
+		}
+	}
+	return p, nil
+}
+
+// String returns p in the canonical syntax.
+func (p pattern) String() string {
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteByte('/')
+		switch seg.kind {
+		case paramSegment:
+			b.WriteByte(':')
+		case catchAllSegment:
+			b.WriteByte('*')
+		}
+		b.WriteString(seg.name)
+	}
+	return b.String()
+}
+
+// ParamForm is the form of named parameters in a dialect.
+type ParamForm int
+
+const (
+	NoParams    ParamForm = iota // static routes only
+	ColonParams                  // /user/:name
+	BraceParams                  // /user/{name}
+)
+
+// CatchAllForm is the form of catch-all parameters in a dialect.
+type CatchAllForm int
+
+const (
+	NoCatchAll        CatchAllForm = iota
+	NamedCatchAll                  // /src/*path
+	StarCatchAll                   // /src/*, unnamed
+	GlobCatchAll                   // /src/**, unnamed
+	RegexpCatchAll                 // /src/{path:.*}, a parameter constrained by a regular expression
+	BraceStarCatchAll              // /src/{path:*}
+)
+
+// Dialect is the route pattern syntax of a router.
+type Dialect struct {
+	Param    ParamForm
+	CatchAll CatchAllForm
+
+	// CatchAllKey is the name the router stores the value of a catch-all
+	// under, if its catch-alls are unnamed.
+	CatchAllKey string
+
+	// AlnumNames restricts parameter names to ASCII letters and digits, for
+	// routers ending a name at the first other character. See paramName.
+	AlnumNames bool
+
+	// Reserved are the characters static segments may not contain, besides
+	// those of the parameter forms.
+	Reserved string
+}
+
+var (
+	// ColonDialect is the canonical syntax.
+	ColonDialect = Dialect{Param: ColonParams, CatchAll: NamedCatchAll}
+
+	// StaticDialect has no parameters. Braces are reserved, since
+	// http.ServeMux reads them as wildcards since Go 1.22.
+	StaticDialect = Dialect{Reserved: "{}"}
+)
+
+// reserved returns the characters static segments may not contain in d.
+func (d Dialect) reserved() string {
+	r := d.Reserved
+	add := func(chars string) {
+		for _, c := range chars {
+			if !strings.ContainsRune(r, c) {
+				r += string(c)
+			}
+		}
+	}
+	switch d.Param {
+	case ColonParams:
+		add(":*")
+	case BraceParams:
+		add("{}")
+	}
+	switch d.CatchAll {
+	case NamedCatchAll, StarCatchAll, GlobCatchAll:
+		add("*")
+	case RegexpCatchAll, BraceStarCatchAll:
+		add("{}")
+	}
+	return r
+}
+
+// pattern translates a path from the canonical syntax to d, renaming the
+// parameters whose names d does not delimit; see paramName. It fails for
+// paths not in the canonical syntax and for patterns d can not express: a
+// parameter or catch-all in a dialect without them, parameter names which
+// are empty or the same once renamed, or a static segment containing a
+// character with a meaning in d.
+func (d Dialect) pattern(path string) (string, error) {
+	p, err := parsePattern(path)
+	if err != nil {
+		return "", err
+	}
+	return d.translate(p)
+}
+
+// translate returns p in the syntax of d.
+func (d Dialect) translate(p pattern) (string, error) {
+	reserved := d.reserved()
+	names := make(map[string]string)
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteByte('/')
+		name := d.paramName(seg)
+		if seg.kind != staticSegment {
+			if name == "" {
+				return "", fmt.Errorf("%s: parameter name %q has no characters the dialect allows", p, seg.name)
+			}
+			if other, ok := names[name]; ok {
+				return "", fmt.Errorf("%s: parameter names %q and %q are both %q in the dialect", p, other, seg.name, name)
+			}
+			names[name] = seg.name
+		}
+		switch seg.kind {
+		case staticSegment:
+			if strings.ContainsAny(seg.name, reserved) {
+				return "", fmt.Errorf("%s: static segment %q contains one of %q", p, seg.name, reserved)
+			}
+			b.WriteString(seg.name)
+
+		case paramSegment:
+			switch d.Param {
+			case NoParams:
+				return "", fmt.Errorf("%s: no parameters in the dialect", p)
+			case ColonParams:
+				b.WriteString(":" + name)
+			case BraceParams:
+				b.WriteString("{" + name + "}")
+			}
+
+		case catchAllSegment:
+			switch d.CatchAll {
+			case NoCatchAll:
+				return "", fmt.Errorf("%s: no catch-alls in the dialect", p)
+			case NamedCatchAll:
+				b.WriteString("*" + name)
+			case StarCatchAll:
+				b.WriteString("*")
+			case GlobCatchAll:
+				b.WriteString("**")
+			case RegexpCatchAll:
+				b.WriteString("{" + name + ":.*}")
+			case BraceStarCatchAll:
+				b.WriteString("{" + name + ":*}")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// paramName returns the name of the parameter or catch-all seg in d. Since
+// a name does not change which requests a route matches, it leaves out the
+// characters of the name d does not delimit it at: any but ASCII letters,
+// digits and underscores in the colon forms, braces and colons in the brace
+// forms, and underscores as well with AlnumNames. The name of a static seg
+// is empty.
+func (d Dialect) paramName(seg segment) string {
+	colon := seg.kind == paramSegment && d.Param == ColonParams ||
+		seg.kind == catchAllSegment && d.CatchAll == NamedCatchAll
+	if seg.kind == staticSegment {
+		return ""
+	}
+	return strings.Map(func(c rune) rune {
+		alnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		switch {
+		case alnum, c == '_' && !d.AlnumNames:
+			return c
+		case colon, d.AlnumNames, strings.ContainsRune("{}:", c):
+			return -1
+		}
+		return c
+	}, seg.name)
+}
+
+// paramKey returns the name the router stores the value of seg under.
+func (d Dialect) paramKey(seg segment) string {
+	if seg.kind == catchAllSegment && d.CatchAllKey != "" {
+		return d.CatchAllKey
+	}
+	return d.paramName(seg)
+}
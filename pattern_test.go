@@ -0,0 +1,125 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	for _, path := range []string{"/", "/user/:name", "/repos/:owner/:repo/contents/*path", "/users/"} {
+		p, err := parsePattern(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if p.String() != path {
+			t.Errorf("%s parsed to %s", path, p)
+		}
+	}
+	for _, path := range []string{"user", "/a/:", "/a/*", "/a/:id/:id", "/src/*path/raw"} {
+		if _, err := parsePattern(path); err == nil {
+			t.Errorf("no error for %s", path)
+		}
+	}
+}
+
+func TestDialectPatternRenames(t *testing.T) {
+	tests := []struct {
+		d       Dialect
+		path    string
+		pattern string
+		key     string
+	}{
+		{gojiDialect, "/pets/:pet-id", "/pets/:petid", "petid"},
+		{gorillaMuxDialect, "/time/:hh:mm", "/time/{hhmm}", "hhmm"},
+		{gorillaMuxDialect, "/pets/:pet-id", "/pets/{pet-id}", "pet-id"},
+		{macaronDialect, "/users/:target_user", "/users/:targetuser", "targetuser"},
+		{ColonDialect, "/src/*file.name", "/src/*filename", "filename"},
+	}
+	for _, test := range tests {
+		pattern, err := test.d.pattern(test.path)
+		if err != nil || pattern != test.pattern {
+			t.Errorf("%s: pattern %s, %v, want %s", test.path, pattern, err, test.pattern)
+		}
+		p, _ := parsePattern(test.path)
+		if key := test.d.paramKey(p[len(p)-1]); key != test.key {
+			t.Errorf("%s: key %s, want %s", test.path, key, test.key)
+		}
+	}
+}
+
+func TestDialectPatternErrors(t *testing.T) {
+	tests := []struct {
+		d    Dialect
+		path string
+		err  string
+	}{
+		{StaticDialect, "/user/:name", "/user/:name: no parameters in the dialect"},
+		{StaticDialect, "/files/{name}", `/files/{name}: static segment "{name}" contains one of "{}"`},
+		{Dialect{Param: ColonParams}, "/src/*path", "/src/*path: no catch-alls in the dialect"},
+		{gojiDialect, "/a/:a-b/:ab", `/a/:a-b/:ab: parameter names "a-b" and "ab" are both "ab" in the dialect`},
+		{gojiDialect, "/a/:-", `/a/:-: parameter name "-" has no characters the dialect allows`},
+		{gojiDialect, "/a:b", `/a:b: static segment "a:b" contains one of ":*"`},
+		{gorillaMuxDialect, "/files/{name}", `/files/{name}: static segment "{name}" contains one of "{}"`},
+		{ColonDialect, "/a/:id/:id", "parameter id repeated in /a/:id/:id"},
+	}
+	for _, test := range tests {
+		_, err := test.d.pattern(test.path)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: error %v, want %s", test.path, err, test.err)
+		}
+	}
+}
+
+// The dialect of a router must express every route of the suites its
+// capabilities let it load.
+func TestDialectsExpressCapabilities(t *testing.T) {
+	for _, r := range routers {
+		d := r.Dialect()
+		for _, s := range suites {
+			for i, needs := range routeNeeds(s.routes) {
+				if needs&^r.Capabilities() != 0 {
+					continue
+				}
+				if _, err := d.pattern(s.routes[i].path); err != nil {
+					t.Errorf("%s on %s: %v", r.Name(), s.name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestFilterRoutesByDialect(t *testing.T) {
+	routes := []route{
+		{"GET", "/pets/:id"},
+		{"GET", "/pets/:pet-id/photos"},
+	}
+	for _, r := range []Router{gojiRouter{}, gorillaMuxRouter{}} {
+		loaded, _ := filterRoutes(r, routes)
+		if len(loaded) != 2 {
+			t.Errorf("%s loads %v, want both", r.Name(), loaded)
+		}
+		if err := verify(r, routes, genRequests(routes, requestSeed)); err != nil {
+			t.Errorf("%s: %v", r.Name(), err)
+		}
+	}
+
+	// Macaron routes the GitHub routes with underscores in parameter names,
+	// such as /applications/:client_id/tokens, once they are renamed
+	loaded, excluded := filterRoutes(macaronRouter{}, githubSuite.routes)
+	if len(excluded) != 0 {
+		t.Errorf("Macaron excludes %d GitHub routes", len(excluded))
+	}
+	var rs []route
+	var reqs []request
+	for _, i := range loaded {
+		rs = append(rs, githubSuite.routes[i])
+		reqs = append(reqs, githubSuite.requests[i])
+	}
+	if err := verify(macaronRouter{}, rs, reqs); err != nil {
+		t.Errorf("Macaron on GithubAPI: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+// Swagger Petstore
+// https://petstore.swagger.io/v2/swagger.json
+//
+// The OpenAPI 2 document of the Swagger sample API, trimmed to what the
+// routes are read from.
+const petstoreDocument = `{
+  "swagger": "2.0",
+  "info": {"title": "Swagger Petstore", "version": "1.0.6"},
+  "host": "petstore.swagger.io",
+  "basePath": "/v2",
+  "paths": {
+    "/pet/{petId}/uploadImage": {
+      "post": {"operationId": "uploadFile", "parameters": [{"name": "petId", "in": "path", "required": true, "type": "integer"}]}
+    },
+    "/pet": {
+      "post": {"operationId": "addPet"},
+      "put": {"operationId": "updatePet"}
+    },
+    "/pet/findByStatus": {
+      "get": {"operationId": "findPetsByStatus"}
+    },
+    "/pet/findByTags": {
+      "get": {"operationId": "findPetsByTags"}
+    },
+    "/pet/{petId}": {
+      "get": {"operationId": "getPetById", "parameters": [{"name": "petId", "in": "path", "required": true, "type": "integer"}]},
+      "post": {"operationId": "updatePetWithForm", "parameters": [{"name": "petId", "in": "path", "required": true, "type": "integer"}]},
+      "delete": {"operationId": "deletePet", "parameters": [{"name": "petId", "in": "path", "required": true, "type": "integer"}]}
+    },
+    "/store/inventory": {
+      "get": {"operationId": "getInventory"}
+    },
+    "/store/order": {
+      "post": {"operationId": "placeOrder"}
+    },
+    "/store/order/{orderId}": {
+      "get": {"operationId": "getOrderById", "parameters": [{"name": "orderId", "in": "path", "required": true, "type": "integer"}]},
+      "delete": {"operationId": "deleteOrder", "parameters": [{"name": "orderId", "in": "path", "required": true, "type": "integer"}]}
+    },
+    "/user/createWithList": {
+      "post": {"operationId": "createUsersWithListInput"}
+    },
+    "/user/{username}": {
+      "get": {"operationId": "getUserByName", "parameters": [{"name": "username", "in": "path", "required": true, "type": "string"}]},
+      "put": {"operationId": "updateUser", "parameters": [{"name": "username", "in": "path", "required": true, "type": "string"}]},
+      "delete": {"operationId": "deleteUser", "parameters": [{"name": "username", "in": "path", "required": true, "type": "string"}]}
+    },
+    "/user/login": {
+      "get": {"operationId": "loginUser"}
+    },
+    "/user/logout": {
+      "get": {"operationId": "logoutUser"}
+    },
+    "/user/createWithArray": {
+      "post": {"operationId": "createUsersWithArrayInput"}
+    },
+    "/user": {
+      "post": {"operationId": "createUser"}
+    }
+  }
+}`
+
+var petstoreAPI = mustParseOpenAPI(petstoreDocument)
+
+var petstoreSuite = registerSuite(&suite{
+	name:   "PetstoreAPI",
+	routes: petstoreAPI,
+	benchmarks: []benchmark{
+		{"PetstoreStatic", "/v2/store/inventory"},
+		{"PetstoreParam", "/v2/pet/123456"},
+		{"PetstoreAll", ""},
+	},
+})
@@ -0,0 +1,24 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+// Static
+func BenchmarkPetstoreStatic(b *testing.B) {
+	petstoreSuite.run(b, "PetstoreStatic")
+}
+
+// Param
+func BenchmarkPetstoreParam(b *testing.B) {
+	petstoreSuite.run(b, "PetstoreParam")
+}
+
+// All routes
+func BenchmarkPetstoreAll(b *testing.B) {
+	petstoreSuite.run(b, "PetstoreAll")
+}
@@ -0,0 +1,275 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The README marks the sections generated from results with comments:
+//
+//	<!-- results: system -->          the Benchmark System list
+//	<!-- results: memory -->          the memory consumption table
+//	<!-- results: ParseStatic ... --> a block with the named benchmarks
+//	<!-- /results -->                 the end of a section
+const (
+	readmeBegin = "<!-- results: "
+	readmeEnd   = "<!-- /results -->"
+)
+
+// memoryColumns are the suites of the memory table with their titles.
+var memoryColumns = []struct {
+	suite, title string
+}{
+	{"Static", "Static"},
+	{"GithubAPI", "GitHub"},
+	{"GPlusAPI", "Google+"},
+	{"ParseAPI", "Parse"},
+}
+
+func readmeCommand(args []string) error {
+	flags := newFlagSet("readme", "results")
+	file := flags.String("file", "README.md", "the README to update")
+	stdout := flags.Bool("print", false, "print the updated README instead of writing it")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rr, err := readResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	readme, err := ioutil.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	out, missing, err := updateReadme(string(readme), rr)
+	if err != nil {
+		return err
+	}
+	for _, section := range missing {
+		fmt.Fprintf(os.Stderr, "no results for section %q, left unchanged\n", section)
+	}
+	if *stdout {
+		_, err = os.Stdout.WriteString(out)
+		return err
+	}
+	return ioutil.WriteFile(*file, []byte(out), 0644)
+}
+
+// updateReadme replaces the marked sections of readme with ones generated
+// from rr. Sections rr has no results for are left unchanged and returned
+// as missing.
+func updateReadme(readme string, rr *runResults) (out string, missing []string, err error) {
+	results := reportResults(rr.Results)
+	var b strings.Builder
+	for {
+		i := strings.Index(readme, readmeBegin)
+		if i < 0 {
+			b.WriteString(readme)
+			return b.String(), missing, nil
+		}
+		nl := strings.Index(readme[i:], "\n")
+		end := strings.Index(readme[i:], readmeEnd)
+		if nl < 0 || end < nl {
+			return "", nil, fmt.Errorf("unterminated section at %q", readme[i:])
+		}
+		header := readme[i : i+nl+1]
+		section := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(header), readmeBegin), " -->")
+		b.WriteString(readme[:i])
+		b.WriteString(header)
+
+		var gen string
+		switch section {
+		case "system":
+			gen = readmeSystem(rr.Env)
+		case "memory":
+			gen = readmeMemory(results)
+		default:
+			gen = readmeBlock(results, strings.Fields(section))
+		}
+		if gen == "" {
+			missing = append(missing, section)
+			gen = readme[i+nl+1 : i+end]
+		}
+		b.WriteString(gen)
+		readme = readme[i+end:]
+	}
+}
+
+func readmeSystem(env environment) string {
+	if env.GoVersion == "" && env.CPU == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Benchmark System:\n\n")
+	if env.CPU != "" {
+		fmt.Fprintf(&b, " * %s", env.CPU)
+		if env.Cores > 0 {
+			fmt.Fprintf(&b, ", %d cores, %d threads", env.Cores, env.Threads)
+		}
+		b.WriteString("\n")
+	}
+	if env.GoVersion != "" {
+		fmt.Fprintf(&b, " * go version %s %s/%s\n", env.GoVersion, env.GOOS, env.GOARCH)
+	} else if env.GOOS != "" {
+		fmt.Fprintf(&b, " * %s/%s\n", env.GOOS, env.GOARCH)
+	}
+	if env.GOMAXPROCS > 0 {
+		fmt.Fprintf(&b, " * GOMAXPROCS=%d GOGC=%s\n", env.GOMAXPROCS, env.GOGC)
+	}
+	if env.Commit != "" {
+		fmt.Fprintf(&b, " * benchmark commit %s\n", env.Commit)
+	}
+	if len(env.Routers) > 0 {
+		b.WriteString(" * router versions:\n")
+		for _, r := range routers {
+			if v, ok := env.Routers[r.Name()]; ok {
+				fmt.Fprintf(&b, "   * %s: %s\n", r.Name(), v)
+			}
+		}
+	}
+	return b.String()
+}
+
+// readmeMemory returns the memory table with the routers as rows and the
+// memoryColumns as columns. The best 3 values of each column are bold.
+func readmeMemory(results []result) string {
+	mem := make(map[string]map[string]uint64) // by suite and router
+	for _, res := range results {
+		if res.Memory == nil {
+			continue
+		}
+		if mem[res.Suite] == nil {
+			mem[res.Suite] = make(map[string]uint64)
+		}
+		mem[res.Suite][res.Router] = res.Memory.Bytes
+	}
+	if len(mem) == 0 {
+		return ""
+	}
+
+	var rows []string
+	for _, r := range routers {
+		for _, col := range memoryColumns {
+			if _, ok := mem[col.suite][r.Name()]; ok {
+				rows = append(rows, r.Name())
+				break
+			}
+		}
+	}
+
+	// cells[0] is the header
+	cells := [][]string{{"Router"}}
+	for _, col := range memoryColumns {
+		cells[0] = append(cells[0], col.title)
+	}
+	for _, row := range rows {
+		cells = append(cells, []string{row})
+	}
+	for _, col := range memoryColumns {
+		var values []uint64
+		for _, v := range mem[col.suite] {
+			values = append(values, v)
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		for i, row := range rows {
+			v, ok := mem[col.suite][row]
+			switch {
+			case !ok:
+				cells[i+1] = append(cells[i+1], "-  ")
+			case len(values) > 3 && v > values[2]:
+				cells[i+1] = append(cells[i+1], fmt.Sprintf("  %d B  ", v))
+			default:
+				cells[i+1] = append(cells[i+1], fmt.Sprintf("__%d B__", v))
+			}
+		}
+	}
+
+	// the header cells are padded by a space on both sides
+	widths := make([]int, len(cells[0]))
+	for r, row := range cells {
+		for i, cell := range row {
+			if i == 0 || r == 0 {
+				cell = " " + cell + " "
+			}
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	var b strings.Builder
+	for r, row := range cells {
+		b.WriteString("|")
+		for i, cell := range row {
+			if i == 0 || r == 0 {
+				fmt.Fprintf(&b, " %-*s |", widths[i]-2, cell)
+			} else {
+				fmt.Fprintf(&b, "%*s|", widths[i], cell)
+			}
+		}
+		b.WriteString("\n")
+		if r == 0 {
+			b.WriteString("|")
+			for i, w := range widths {
+				if i == 0 {
+					fmt.Fprintf(&b, ":%s|", strings.Repeat("-", w-1))
+				} else {
+					fmt.Fprintf(&b, "%s:|", strings.Repeat("-", w-1))
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// readmeBlock returns a code block with the results of the named
+// benchmarks in the layout of go test, separated by empty lines.
+func readmeBlock(results []result, names []string) string {
+	width := 0
+	for _, res := range results {
+		for _, name := range names {
+			if res.Benchmark == name && len(res.benchName()) > width {
+				width = len(res.benchName())
+			}
+		}
+	}
+
+	var groups []string
+	for _, name := range names {
+		var b strings.Builder
+		for _, res := range results {
+			if res.Benchmark != name {
+				continue
+			}
+			if res.Error != "" {
+				fmt.Fprintf(&b, "%-*s\t%s\n", width, res.benchName(), res.Error)
+				continue
+			}
+			fmt.Fprintf(&b, "%-*s\t%8d\t%10.0f ns/op\t%6.1f %%routes\t%8d B/op\t%8d allocs/op\n",
+				width, res.benchName(), res.N, res.NsPerOp, res.Coverage, res.BytesPerOp, res.AllocsPerOp)
+		}
+		if b.Len() > 0 {
+			groups = append(groups, b.String())
+		}
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+	return "```\n" + strings.Join(groups, "\n") + "```\n"
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateReadme(t *testing.T) {
+	const readme = `Intro
+
+<!-- results: system -->
+old system
+<!-- /results -->
+
+<!-- results: memory -->
+old table
+<!-- /results -->
+
+<!-- results: Param GithubAll -->
+old block
+<!-- /results -->
+
+<!-- results: ParseAll -->
+kept block
+<!-- /results -->
+`
+	mem := func(b uint64) *memory { return &memory{Bytes: b} }
+	rr := &runResults{
+		Env: environment{GoVersion: "go1.7.5", GOOS: "darwin", GOARCH: "amd64", CPU: "2 GHz Intel Core i7"},
+		Results: []result{
+			{Benchmark: "Param", Router: "Goji", Mode: "serial", Procs: 1, N: 2000000, NsPerOp: 790, BytesPerOp: 336, AllocsPerOp: 2, Coverage: 100},
+			{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Beego", Mode: "serial", Procs: 1, Memory: mem(145080)},
+			{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Goji", Mode: "serial", Procs: 1, Memory: mem(86088)},
+			{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "GorillaMux", Mode: "serial", Procs: 1, Memory: mem(1494864)},
+			{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Martini", Mode: "serial", Procs: 1, Memory: mem(476960)},
+			{Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Broken", Mode: "serial", Procs: 1, Error: "FAILED routing verification"},
+			{Suite: "Static", Benchmark: "StaticAll", Router: "HttpServeMux", Mode: "serial", Procs: 1, Memory: mem(17344)},
+			{Suite: "Static", Benchmark: "StaticAll", Router: "Goji", Mode: "parallel", Procs: 1, Memory: mem(1)},
+		},
+	}
+
+	out, missing, err := updateReadme(readme, rr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "ParseAll" {
+		t.Errorf("missing sections %q, want [ParseAll]", missing)
+	}
+
+	for _, want := range []string{
+		"<!-- results: system -->\nBenchmark System:\n\n * 2 GHz Intel Core i7\n * go version go1.7.5 darwin/amd64\n<!-- /results -->\n",
+		"<!-- results: memory -->\n" +
+			"| Router       | Static    | GitHub      | Google+ | Parse |\n" +
+			"|:-------------|----------:|------------:|--------:|------:|\n" +
+			"| Beego        |        -  | __145080 B__|      -  |    -  |\n" +
+			"| Goji         |        -  |  __86088 B__|      -  |    -  |\n" +
+			"| GorillaMux   |        -  |  1494864 B  |      -  |    -  |\n" +
+			"| Martini      |        -  | __476960 B__|      -  |    -  |\n" +
+			"| HttpServeMux |__17344 B__|          -  |      -  |    -  |\n" +
+			"<!-- /results -->\n",
+		"<!-- results: Param GithubAll -->\n```\n" +
+			"BenchmarkParam/Goji          \t 2000000\t       790 ns/op\t 100.0 %routes\t     336 B/op\t       2 allocs/op\n" +
+			"\n",
+		"BenchmarkGithubAll/Broken    \tFAILED routing verification\n```\n<!-- /results -->\n",
+		"<!-- results: ParseAll -->\nkept block\n<!-- /results -->\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("README lacks\n%s\ngot\n%s", want, out)
+		}
+	}
+
+	if _, _, err := updateReadme("<!-- results: memory -->\nno end\n", rr); err == nil {
+		t.Error("unterminated section not detected")
+	}
+}
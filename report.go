@@ -0,0 +1,304 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func reportCommand(args []string) error {
+	flags := newFlagSet("report", "results")
+	out := flags.String("o", "report.html", "write the report to `file`")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rr, err := readResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	w, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(w, rr); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// reportData is what the report template shows.
+type reportData struct {
+	Env      [][2]string
+	Overview template.HTML
+	Memory   reportMemory
+	Suites   []reportSuite
+}
+
+type reportSuite struct {
+	Name   string
+	Routes int
+	Rows   []reportRow
+	Charts []template.HTML
+	Status []reportStatus
+}
+
+// reportRow is a result in the table of a suite.
+type reportRow struct {
+	Benchmark string
+	Router    string
+	NsPerOp   float64
+	BytesOp   int64
+	AllocsOp  int64
+	Coverage  float64
+	Relative  float64
+}
+
+// reportStatus is the verification status and coverage of a router for a
+// suite.
+type reportStatus struct {
+	Router   string
+	Status   string
+	OK       bool
+	Routes   int
+	Skipped  int
+	Coverage float64
+}
+
+// reportMemory is the memory table, with a row per router and a column per
+// suite.
+type reportMemory struct {
+	Suites []string
+	Rows   []reportMemoryRow
+}
+
+type reportMemoryRow struct {
+	Router string
+	Cells  []reportMemoryCell
+}
+
+type reportMemoryCell struct {
+	Bytes    uint64
+	PerRoute float64
+	OK       bool
+}
+
+// writeReport writes the HTML report of rr. It has no external assets: the
+// charts are inline SVG, the tables are sorted by an inline script.
+func writeReport(w io.Writer, rr *runResults) error {
+	results := reportResults(rr.Results)
+	data := reportData{Env: rr.Env.config()}
+
+	charts := resultCharts(results)
+	svg := func(name string) template.HTML {
+		c := charts[name]
+		if c == nil {
+			return ""
+		}
+		var buf bytes.Buffer
+		c.writeSVG(&buf)
+		return template.HTML(buf.String())
+	}
+	data.Overview = svg("overview.svg")
+
+	memSuites := make(map[string]bool)
+	for _, s := range suites {
+		rs := reportSuite{Name: s.name, Routes: len(s.routes)}
+		fastest := make(map[string]float64)
+		var suiteResults []result
+		for _, res := range results {
+			if findBenchmarkSuite(res.Benchmark) != s {
+				continue
+			}
+			suiteResults = append(suiteResults, res)
+			if res.Error == "" && (fastest[res.Benchmark] == 0 || res.NsPerOp < fastest[res.Benchmark]) {
+				fastest[res.Benchmark] = res.NsPerOp
+			}
+			if res.Memory != nil {
+				memSuites[s.name] = true
+			}
+		}
+		if len(suiteResults) == 0 {
+			continue
+		}
+
+		status := make(map[string]*reportStatus)
+		for _, res := range suiteResults {
+			st := status[res.Router]
+			if st == nil {
+				st = &reportStatus{Router: res.Router, Status: "ok", OK: true}
+				status[res.Router] = st
+			}
+			if res.Error != "" {
+				st.Status, st.OK = res.Error, false
+				continue
+			}
+			st.Routes, st.Coverage = res.Routes, res.Coverage
+			if st.Routes == 0 {
+				st.Routes = int(res.Coverage/100*float64(len(s.routes)) + 0.5)
+			}
+			st.Skipped = len(s.routes) - st.Routes
+			rs.Rows = append(rs.Rows, reportRow{
+				Benchmark: res.Benchmark,
+				Router:    res.Router,
+				NsPerOp:   res.NsPerOp,
+				BytesOp:   res.BytesPerOp,
+				AllocsOp:  res.AllocsPerOp,
+				Coverage:  res.Coverage,
+				Relative:  res.NsPerOp / fastest[res.Benchmark],
+			})
+		}
+		for _, r := range routers {
+			switch st := status[r.Name()]; {
+			case st != nil:
+				rs.Status = append(rs.Status, *st)
+				delete(status, r.Name())
+			case !supports(r, s.routes):
+				rs.Status = append(rs.Status, reportStatus{Router: r.Name(), Status: "not supported"})
+			}
+		}
+		// routers of the results which are not registered, e.g. since
+		// they were removed after the run
+		for _, res := range suiteResults {
+			if st := status[res.Router]; st != nil {
+				rs.Status = append(rs.Status, *st)
+				delete(status, res.Router)
+			}
+		}
+
+		for _, m := range chartMetrics {
+			if c := svg(strings.ToLower(s.name) + "-" + m.name + ".svg"); c != "" {
+				rs.Charts = append(rs.Charts, c)
+			}
+		}
+		data.Suites = append(data.Suites, rs)
+	}
+
+	for _, s := range suites {
+		if memSuites[s.name] {
+			data.Memory.Suites = append(data.Memory.Suites, s.name)
+		}
+	}
+	for _, r := range routers {
+		row := reportMemoryRow{Router: r.Name()}
+		found := false
+		for _, name := range data.Memory.Suites {
+			var cell reportMemoryCell
+			for _, res := range results {
+				if res.Router == r.Name() && res.Suite == name && res.Memory != nil {
+					cell = reportMemoryCell{res.Memory.Bytes, res.Memory.BytesPerRoute, true}
+					found = true
+					break
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		if found {
+			data.Memory.Rows = append(data.Memory.Rows, row)
+		}
+	}
+
+	return reportTemplate.Execute(w, data)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"float": func(v float64, prec int) string { return strconv.FormatFloat(v, 'f', prec, 64) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Go HTTP Router Benchmark</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { padding: 3px 10px; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child, td.text { text-align: left; }
+table.sortable th { cursor: pointer; background: #f4f4f4; }
+table.sortable th.asc::after { content: " ▲"; }
+table.sortable th.desc::after { content: " ▼"; }
+td.failed { color: #c00; }
+.charts svg { margin: 0 1em 1em 0; }
+</style>
+</head>
+<body>
+<h1>Go HTTP Router Benchmark</h1>
+
+<h2>Environment</h2>
+<table>
+{{range .Env}}<tr><td>{{index . 0}}</td><td class="text">{{index . 1}}</td></tr>
+{{end}}</table>
+
+{{with .Overview}}<h2>Overview</h2>
+<div class="charts">{{.}}</div>
+{{end}}
+{{with .Memory.Rows}}<h2>Memory Consumption</h2>
+<table class="sortable">
+<thead><tr><th>Router</th>{{range $.Memory.Suites}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .}}<tr><td>{{.Router}}</td>{{range .Cells}}{{if .OK}}<td data-sort="{{.Bytes}}">{{.Bytes}} B ({{float .PerRoute 0}} B/route)</td>{{else}}<td data-sort="">-</td>{{end}}{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+{{range .Suites}}<h2 id="{{.Name}}">{{.Name}}</h2>
+<p>{{.Routes}} routes</p>
+<table class="sortable">
+<thead><tr><th>Benchmark</th><th>Router</th><th>ns/op</th><th>B/op</th><th>allocs/op</th><th>%routes</th><th>relative</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.Benchmark}}</td><td class="text">{{.Router}}</td><td data-sort="{{.NsPerOp}}">{{float .NsPerOp 1}}</td><td>{{.BytesOp}}</td><td>{{.AllocsOp}}</td><td>{{float .Coverage 1}}</td><td data-sort="{{.Relative}}">{{float .Relative 2}}x</td></tr>
+{{end}}</tbody>
+</table>
+<h3>Verification and coverage</h3>
+<table class="sortable">
+<thead><tr><th>Router</th><th>verification</th><th>routes</th><th>skipped</th><th>%routes</th></tr></thead>
+<tbody>
+{{range .Status}}<tr><td>{{.Router}}</td>{{if .OK}}<td class="text">{{.Status}}</td><td>{{.Routes}}</td><td>{{.Skipped}}</td><td>{{float .Coverage 1}}</td>{{else}}<td class="text failed">{{.Status}}</td><td data-sort="">-</td><td data-sort="">-</td><td data-sort="">-</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<div class="charts">{{range .Charts}}{{.}}{{end}}</div>
+{{end}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function(table) {
+	var ths = table.querySelectorAll("th");
+	ths.forEach(function(th, col) {
+		th.addEventListener("click", function() {
+			var asc = !th.classList.contains("asc");
+			ths.forEach(function(h) { h.classList.remove("asc", "desc"); });
+			th.classList.add(asc ? "asc" : "desc");
+			var key = function(row) {
+				var td = row.children[col];
+				var v = td.hasAttribute("data-sort") ? td.getAttribute("data-sort") : td.textContent;
+				var n = parseFloat(v);
+				return isNaN(n) ? v : n;
+			};
+			var tbody = table.tBodies[0];
+			Array.prototype.slice.call(tbody.rows).sort(function(a, b) {
+				var x = key(a), y = key(b);
+				if (typeof x !== typeof y) {
+					return typeof x === "number" ? -1 : 1;
+				}
+				var c = x < y ? -1 : x > y ? 1 : 0;
+				return asc ? c : -c;
+			}).forEach(function(row) { tbody.appendChild(row); });
+		});
+	});
+});
+</script>
+</body>
+</html>
+`))
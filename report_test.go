@@ -0,0 +1,46 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	rr, err := parseBenchOutput(strings.NewReader(benchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the report shows serial results only
+	rr.Results[0].Procs = 1
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, rr); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<td>goos</td><td class=\"text\">linux</td>",
+		"<h2>Memory Consumption</h2>",
+		"<td data-sort=\"98264\">98264 B (437 B/route)</td>",
+		"<h2 id=\"GithubAPI\">GithubAPI</h2>",
+		"<td>GithubAll</td><td class=\"text\">Goji</td><td data-sort=\"550221\">550221.0</td><td>63172</td><td>376</td><td>94.1</td>",
+		// Goji skips the routes needing a static segment next to a parameter
+		"<tr><td>Goji</td><td class=\"text\">ok</td><td>225</td><td>14</td><td>94.1</td></tr>",
+		"<td class=\"text failed\">FAILED routing verification</td>",
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	for _, external := range []string{"src=", "<link", "@import"} {
+		if strings.Contains(out, external) {
+			t.Errorf("report has external asset %q", external)
+		}
+	}
+}
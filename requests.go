@@ -0,0 +1,186 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// requestSeed seeds the parameter values of generated requests, so every run
+// requests the same URLs.
+const requestSeed = 1
+
+// request is a concrete request for a route.
+type request struct {
+	method string
+	path   string
+
+	// params holds the value of each parameter in path.
+	params map[string]string
+}
+
+// genRequests returns a request for each of the routes. The parameters are
+// set to values generated from seed, which look like the values real clients
+// send for parameters of the same name.
+func genRequests(routes []route, seed int64) []request {
+	rnd := rand.New(rand.NewSource(seed))
+	requests := make([]request, len(routes))
+	for i, route := range routes {
+		params := make(map[string]string)
+		for _, seg := range route.segments() {
+			switch seg.kind {
+			case paramSegment:
+				params[seg.name] = genParam(rnd, seg.name)
+			case catchAllSegment:
+				params[seg.name] = genCatchAll(rnd, seg.name)
+			}
+		}
+		requests[i] = request{route.method, requestPath(route, params), params}
+	}
+	return requests
+}
+
+// requestPath returns the path of a request to r with the parameter values
+// params.
+func requestPath(r route, params map[string]string) string {
+	parts := []string{""}
+	for _, seg := range r.segments() {
+		if seg.kind == staticSegment {
+			parts = append(parts, seg.name)
+		} else {
+			parts = append(parts, params[seg.name])
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+const (
+	lower    = "abcdefghijklmnopqrstuvwxyz"
+	upper    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits   = "0123456789"
+	hexDigit = "0123456789abcdef"
+)
+
+// paramGens generates values for parameters by name. Parameters without an
+// entry get a lowercase word.
+var paramGens = map[string]func(rnd *rand.Rand) string{
+	// GitHub
+	"owner":          genString(lower+digits, 3, 12),
+	"user":           genString(lower+digits, 3, 12),
+	"target_user":    genString(lower+digits, 3, 12),
+	"assignee":       genString(lower+digits, 3, 12),
+	"org":            genString(lower+digits, 3, 12),
+	"repo":           genString(lower+"-", 4, 16),
+	"repository":     genString(lower+"-", 4, 16),
+	"sha":            genString(hexDigit, 40, 40),
+	"ref":            genString(hexDigit, 40, 40),
+	"branch":         genString(lower+"-", 4, 12),
+	"client_id":      genString(hexDigit, 20, 20),
+	"access_token":   genString(hexDigit, 40, 40),
+	"number":         genNumber(1, 9999),
+	"id":             genNumber(1, 99999999),
+	"email":          genEmail,
+	"state":          genChoice("open", "closed"),
+	"archive_format": genChoice("tarball", "zipball"),
+
+	// Google+
+	"userId":     genString(digits, 21, 21),
+	"activityId": genString(lower+digits, 33, 33),
+	"commentId":  genString(lower+upper+digits+"-_.", 40, 48),
+	"collection": genChoice("public", "visible", "vault", "connected"),
+
+	// Parse
+	"className": genClassName,
+	"objectId":  genString(lower+upper+digits, 10, 10),
+	"fileName":  genFileName,
+	"eventName": genString(lower, 4, 12),
+
+	// Petstore
+	"petId":    genNumber(1, 999999),
+	"orderId":  genNumber(1, 9999),
+	"username": genString(lower+digits, 3, 12),
+}
+
+func genParam(rnd *rand.Rand, name string) string {
+	if gen, ok := paramGens[name]; ok {
+		return gen(rnd)
+	}
+	return genString(lower, 3, 10)(rnd)
+}
+
+// catchAllGens generates values for catch-all parameters by name. Catch-alls
+// without an entry get one to three lowercase words separated by slashes.
+var catchAllGens = map[string]func(rnd *rand.Rand) string{
+	// GitHub
+	"ref":  genGitRef,
+	"path": genFilePath,
+}
+
+func genCatchAll(rnd *rand.Rand, name string) string {
+	if gen, ok := catchAllGens[name]; ok {
+		return gen(rnd)
+	}
+	return genPath(rnd, genString(lower, 3, 10), 1, 3)
+}
+
+// genPath joins min to max values of gen with slashes.
+func genPath(rnd *rand.Rand, gen func(rnd *rand.Rand) string, min, max int) string {
+	parts := make([]string, min+rnd.Intn(max-min+1))
+	for i := range parts {
+		parts[i] = gen(rnd)
+	}
+	return strings.Join(parts, "/")
+}
+
+// genString generates strings of min to max characters from chars.
+func genString(chars string, min, max int) func(rnd *rand.Rand) string {
+	return func(rnd *rand.Rand) string {
+		b := make([]byte, min+rnd.Intn(max-min+1))
+		for i := range b {
+			b[i] = chars[rnd.Intn(len(chars))]
+		}
+		return string(b)
+	}
+}
+
+// genNumber generates decimal numbers from min to max.
+func genNumber(min, max int) func(rnd *rand.Rand) string {
+	return func(rnd *rand.Rand) string {
+		return strconv.Itoa(min + rnd.Intn(max-min+1))
+	}
+}
+
+// genChoice picks one of values.
+func genChoice(values ...string) func(rnd *rand.Rand) string {
+	return func(rnd *rand.Rand) string {
+		return values[rnd.Intn(len(values))]
+	}
+}
+
+func genEmail(rnd *rand.Rand) string {
+	return genString(lower+digits+".", 3, 16)(rnd) + "@example.com"
+}
+
+func genClassName(rnd *rand.Rand) string {
+	return genString(upper, 1, 1)(rnd) + genString(lower, 3, 11)(rnd)
+}
+
+func genGitRef(rnd *rand.Rand) string {
+	return genChoice("heads", "tags")(rnd) + "/" + genPath(rnd, genString(lower+digits+"-", 3, 12), 1, 2)
+}
+
+func genFilePath(rnd *rand.Rand) string {
+	dir := genPath(rnd, genString(lower+"_", 3, 10), 0, 3)
+	if dir == "" {
+		return genFileName(rnd)
+	}
+	return dir + "/" + genFileName(rnd)
+}
+
+func genFileName(rnd *rand.Rand) string {
+	return genString(lower+digits+"-", 4, 16)(rnd) + genChoice(".jpg", ".png", ".txt")(rnd)
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestGenRequests(t *testing.T) {
+	requests := genRequests(githubAPI, requestSeed)
+	if !reflect.DeepEqual(requests, genRequests(githubAPI, requestSeed)) {
+		t.Fatal("requests differ for the same seed")
+	}
+
+	for i, req := range requests {
+		route := githubAPI[i]
+		if req.method != route.method {
+			t.Errorf("%s %s: method is %s", route.method, route.path, req.method)
+		}
+
+		// the generated path must match the pattern it was generated from
+		var names []string
+		expr := "^"
+		for _, seg := range route.segments() {
+			switch seg.kind {
+			case paramSegment:
+				expr += "/([^/]+)"
+				names = append(names, seg.name)
+			case catchAllSegment:
+				expr += "/(.+)"
+				names = append(names, seg.name)
+			default:
+				expr += "/" + regexp.QuoteMeta(seg.name)
+			}
+		}
+		m := regexp.MustCompile(expr + "$").FindStringSubmatch(req.path)
+		if m == nil {
+			t.Errorf("%s %s: path %s does not match", route.method, route.path, req.path)
+			continue
+		}
+		for j, name := range names {
+			if req.params[name] != m[j+1] {
+				t.Errorf("%s %s: param %s is %q, path contains %q",
+					route.method, route.path, name, req.params[name], m[j+1])
+			}
+		}
+	}
+
+	req := genRequests([]route{{"GET", "/repos/:owner/:repo/git/commits/:sha"}}, requestSeed)[0]
+	if len(req.params["sha"]) != 40 {
+		t.Errorf("sha %q is not 40 characters long", req.params["sha"])
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runResults are the results of a run and the environment it ran in. This
+// is what the runner writes as JSON and the report tools read.
+type runResults struct {
+	Env     environment `json:"environment"`
+	Results []result    `json:"results"`
+}
+
+// result is a single run of a benchmark for a router. Repeated runs, as with
+// -count, are separate results.
+type result struct {
+	Suite     string `json:"suite,omitempty"`
+	Benchmark string `json:"benchmark"`
+	Router    string `json:"router"`
+	Mode      string `json:"mode"`
+
+	// Procs is the GOMAXPROCS the benchmark ran with.
+	Procs int `json:"procs,omitempty"`
+
+	// Error is set if the router failed the routing verification, the
+	// benchmark did not run then.
+	Error string `json:"error,omitempty"`
+
+	N           int     `json:"n,omitempty"`
+	NsPerOp     float64 `json:"ns_per_op,omitempty"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+
+	// NsPerRequest is the time per request, an op sends one request or
+	// one per route of the suite.
+	NsPerRequest float64 `json:"ns_per_request,omitempty"`
+
+	// Routes is the number of routes the router was loaded with, Coverage
+	// their percentage of the route set.
+	Routes   int     `json:"routes,omitempty"`
+	Coverage float64 `json:"coverage,omitempty"`
+
+	// Memory is the memory footprint of the routing structure, if measured.
+	Memory *memory `json:"memory,omitempty"`
+
+	// Extra holds the other metrics reported, by unit.
+	Extra map[string]float64 `json:"extra,omitempty"`
+}
+
+// memory is the memory footprint of a routing structure, see memStats.
+type memory struct {
+	Bytes         uint64  `json:"bytes"`
+	BytesPerRoute float64 `json:"bytes_per_route"`
+	LoadBytes     uint64  `json:"load_bytes"`
+	LoadAllocs    uint64  `json:"load_allocs"`
+	LoadGCs       uint32  `json:"load_gcs"`
+}
+
+// setMetric sets the metric with the given unit, as reported by a benchmark.
+func (res *result) setMetric(unit string, v float64) {
+	mem := func() *memory {
+		if res.Memory == nil {
+			res.Memory = new(memory)
+		}
+		return res.Memory
+	}
+	switch unit {
+	case "ns/op":
+		res.NsPerOp = v
+	case "B/op":
+		res.BytesPerOp = int64(v)
+	case "allocs/op":
+		res.AllocsPerOp = int64(v)
+	case "ns/req":
+		res.NsPerRequest = v
+	case "%routes":
+		res.Coverage = v
+	case "procs":
+		res.Procs = int(v)
+	case "mem-B":
+		mem().Bytes = uint64(v)
+	case "mem-B/route":
+		mem().BytesPerRoute = v
+	case "load-B":
+		mem().LoadBytes = uint64(v)
+	case "load-allocs":
+		mem().LoadAllocs = uint64(v)
+	case "load-GCs":
+		mem().LoadGCs = uint32(v)
+	default:
+		if res.Extra == nil {
+			res.Extra = make(map[string]float64)
+		}
+		res.Extra[unit] = v
+	}
+}
+
+type metric struct {
+	value float64
+	unit  string
+}
+
+// metrics returns the metrics of res in the order go test prints them,
+// with the units setMetric takes.
+func (res *result) metrics() []metric {
+	ms := []metric{{res.NsPerOp, "ns/op"}}
+	if res.NsPerRequest > 0 {
+		ms = append(ms, metric{res.NsPerRequest, "ns/req"})
+	}
+	if res.Coverage > 0 {
+		ms = append(ms, metric{res.Coverage, "%routes"})
+	}
+	if mem := res.Memory; mem != nil {
+		ms = append(ms,
+			metric{float64(mem.Bytes), "mem-B"},
+			metric{mem.BytesPerRoute, "mem-B/route"},
+			metric{float64(mem.LoadBytes), "load-B"},
+			metric{float64(mem.LoadAllocs), "load-allocs"},
+			metric{float64(mem.LoadGCs), "load-GCs"})
+	}
+	units := make([]string, 0, len(res.Extra))
+	for unit := range res.Extra {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	for _, unit := range units {
+		ms = append(ms, metric{res.Extra[unit], unit})
+	}
+	return append(ms, metric{float64(res.BytesPerOp), "B/op"}, metric{float64(res.AllocsPerOp), "allocs/op"})
+}
+
+// key identifies the benchmark of res across runs.
+func (res *result) key() string {
+	return fmt.Sprintf("%s/%s/%s/%d", res.Mode, res.Benchmark, res.Router, res.Procs)
+}
+
+// benchName returns the name go test gives the benchmark of res, including
+// the GOMAXPROCS suffix.
+func (res *result) benchName() string {
+	prefix := "Benchmark"
+	switch res.Mode {
+	case parallel.String():
+		prefix = "BenchmarkParallel/"
+	case endToEnd.String():
+		prefix = "BenchmarkEndToEnd/"
+	}
+	name := prefix + res.Benchmark + "/" + res.Router
+	if res.Procs > 1 {
+		name += "-" + strconv.Itoa(res.Procs)
+	}
+	return name
+}
+
+// reportResults returns the median serial results, of the lowest
+// GOMAXPROCS there are results for. They are the ones the README and the
+// charts show.
+func reportResults(results []result) []result {
+	procs := 0
+	for _, res := range results {
+		if res.Mode == serial.String() && (procs == 0 || res.Procs < procs) {
+			procs = res.Procs
+		}
+	}
+	var serials []result
+	for _, res := range medianResults(results) {
+		if res.Mode == serial.String() && res.Procs == procs {
+			serials = append(serials, res)
+		}
+	}
+	return serials
+}
+
+// writeJSON writes rr as indented JSON.
+func writeJSON(w io.Writer, rr *runResults) error {
+	b, err := json.MarshalIndent(rr, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+var csvHeader = []string{
+	"suite", "benchmark", "router", "mode", "procs", "error",
+	"n", "ns_per_op", "ns_per_request", "bytes_per_op", "allocs_per_op", "routes", "coverage",
+	"mem_bytes", "mem_bytes_per_route", "load_bytes", "load_allocs", "load_gcs",
+	"date", "go_version", "goos", "goarch", "cpu", "cores", "threads", "gomaxprocs", "gogc",
+	"commit", "router_version",
+}
+
+// writeCSV writes rr as CSV with a header line, one line per result. The
+// environment is repeated on each line, with the version of the result's
+// router.
+func writeCSV(w io.Writer, rr *runResults) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, res := range rr.Results {
+		mem := res.Memory
+		if mem == nil {
+			mem = new(memory)
+		}
+		cw.Write([]string{
+			res.Suite, res.Benchmark, res.Router, res.Mode, strconv.Itoa(res.Procs), res.Error,
+			strconv.Itoa(res.N), f(res.NsPerOp), f(res.NsPerRequest), strconv.FormatInt(res.BytesPerOp, 10),
+			strconv.FormatInt(res.AllocsPerOp, 10), strconv.Itoa(res.Routes), f(res.Coverage),
+			strconv.FormatUint(mem.Bytes, 10), f(mem.BytesPerRoute), strconv.FormatUint(mem.LoadBytes, 10),
+			strconv.FormatUint(mem.LoadAllocs, 10), strconv.FormatUint(uint64(mem.LoadGCs), 10),
+			rr.Env.Date.Format(time.RFC3339), rr.Env.GoVersion, rr.Env.GOOS, rr.Env.GOARCH, rr.Env.CPU,
+			strconv.Itoa(rr.Env.Cores), strconv.Itoa(rr.Env.Threads), strconv.Itoa(rr.Env.GOMAXPROCS),
+			rr.Env.GOGC, rr.Env.Commit, rr.Env.Routers[res.Router],
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readResults reads results written by writeJSON or, if the input does not
+// start with a JSON object, parses it as go test -bench output.
+func readResults(r io.Reader) (*runResults, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		rr := new(runResults)
+		if err := json.Unmarshal(b, rr); err != nil {
+			return nil, err
+		}
+		return rr, nil
+	}
+	return parseBenchOutput(bytes.NewReader(b))
+}
+
+// parseBenchOutput parses the output of go test -bench, of the current and
+// of the former BenchmarkRouter_Benchmark naming. Lines other than results
+// and the configuration lines printed by go test and printConfig are
+// ignored, so are the results of BenchmarkScaling.
+func parseBenchOutput(r io.Reader) (*runResults, error) {
+	rr := new(runResults)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if i := strings.Index(text, ": "); i > 0 && !strings.ContainsAny(text[:i], " \t") {
+			rr.Env.setConfig(text[:i], text[i+2:])
+			continue
+		}
+		if !strings.HasPrefix(text, "Benchmark") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		res, ok := parseBenchName(fields[0])
+		if !ok {
+			continue
+		}
+		s := findBenchmarkSuite(res.Benchmark)
+		if s != nil {
+			res.Suite = s.name
+		}
+		if strings.Join(fields[1:], " ") == "FAILED routing verification" {
+			res.Error = "FAILED routing verification"
+			rr.Results = append(rr.Results, res)
+			continue
+		}
+		if len(fields) < 4 || len(fields)%2 != 0 {
+			continue
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid iteration count %q", line, fields[1])
+		}
+		res.N = n
+		for i := 2; i < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q", line, fields[i])
+			}
+			res.setMetric(fields[i+1], v)
+		}
+		if s != nil {
+			res.Routes = int(math.Floor(res.Coverage/100*float64(len(s.routes)) + 0.5))
+		}
+		rr.Results = append(rr.Results, res)
+	}
+	return rr, sc.Err()
+}
+
+// parseBenchName parses a benchmark name as printed by go test -bench.
+func parseBenchName(name string) (result, bool) {
+	// go test leaves out the GOMAXPROCS suffix if it is 1
+	res := result{Mode: serial.String(), Procs: 1}
+	name = strings.TrimPrefix(name, "Benchmark")
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		if procs, err := strconv.Atoi(name[i+1:]); err == nil {
+			res.Procs = procs
+			name = name[:i]
+		}
+	}
+
+	parts := strings.Split(name, "/")
+	switch parts[0] {
+	case "Parallel":
+		res.Mode = parallel.String()
+		parts = parts[1:]
+	case "EndToEnd":
+		res.Mode = endToEnd.String()
+		parts = parts[1:]
+	case "Scaling":
+		return res, false
+	}
+	switch len(parts) {
+	case 1:
+		// BenchmarkGoji_GithubAll
+		i := strings.Index(parts[0], "_")
+		if i < 0 {
+			return res, false
+		}
+		res.Router, res.Benchmark = parts[0][:i], parts[0][i+1:]
+	case 2:
+		res.Benchmark, res.Router = parts[0], parts[1]
+	default:
+		return res, false
+	}
+	return res, true
+}
+
+// findBenchmarkSuite returns the suite with the named benchmark.
+func findBenchmarkSuite(name string) *suite {
+	for _, s := range suites {
+		for _, bm := range s.benchmarks {
+			if bm.name == name {
+				return s
+			}
+		}
+	}
+	return nil
+}
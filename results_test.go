@@ -0,0 +1,104 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const benchOutput = `#GithubAPI Routes: 239
+   Goji: 98264 Bytes (437 Bytes/route), allocated 1689040 Bytes in 3503 allocs, 0 GCs
+goos: linux
+goarch: amd64
+pkg: github.com/julienschmidt/go-http-routing-benchmark
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkGithubAll/Goji-4         	     100	    550221 ns/op	        94.14 %routes	   1689040 load-B	         0 load-GCs	      3503 load-allocs	     98264 mem-B	       436.7 mem-B/route	   63172 B/op	     376 allocs/op
+BenchmarkGithubAll/Broken	FAILED routing verification
+BenchmarkParallel/GPlusParam/Beego	    2000	       761.2 ns/op	       100.0 %routes	         4.000 procs	     352 B/op	       3 allocs/op
+BenchmarkScaling/GPlusAll/Goji/procs=2	    2000	     10856 ns/op	       100.0 %routes	   1202499 req/s	         0.9625 speedup	    3696 B/op	      22 allocs/op
+BenchmarkMartini_GPlusParam2     	  100000	     13707 ns/op	    1232 B/op	      15 allocs/op
+PASS
+`
+
+func TestParseBenchOutput(t *testing.T) {
+	rr, err := parseBenchOutput(strings.NewReader(benchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.Env.GOOS != "linux" || rr.Env.GOARCH != "amd64" || rr.Env.CPU != "Intel(R) Xeon(R) Processor" {
+		t.Errorf("environment %+v", rr.Env)
+	}
+
+	want := []result{
+		{
+			Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Goji", Mode: "serial", Procs: 4,
+			N: 100, NsPerOp: 550221, BytesPerOp: 63172, AllocsPerOp: 376, Routes: 225, Coverage: 94.14,
+			Memory: &memory{98264, 436.7, 1689040, 3503, 0},
+		},
+		{
+			Suite: "GithubAPI", Benchmark: "GithubAll", Router: "Broken", Mode: "serial", Procs: 1,
+			Error: "FAILED routing verification",
+		},
+		{
+			Suite: "GPlusAPI", Benchmark: "GPlusParam", Router: "Beego", Mode: "parallel", Procs: 4,
+			N: 2000, NsPerOp: 761.2, BytesPerOp: 352, AllocsPerOp: 3, Routes: 13, Coverage: 100,
+		},
+		{
+			Benchmark: "GPlusParam2", Router: "Martini", Mode: "serial", Procs: 1,
+			N: 100000, NsPerOp: 13707, BytesPerOp: 1232, AllocsPerOp: 15,
+		},
+	}
+	if !reflect.DeepEqual(rr.Results, want) {
+		t.Errorf("results\n%+v\nwant\n%+v", rr.Results, want)
+	}
+}
+
+func TestResultsRoundTrip(t *testing.T) {
+	rr, err := parseBenchOutput(strings.NewReader(benchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr.Results[0].Extra = map[string]float64{"req/s": 1e6}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, rr); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readResults(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rr) {
+		t.Errorf("JSON round trip\n%+v\nwant\n%+v", got, rr)
+	}
+
+	// the go test format of printBench parses back to the same results,
+	// apart from the environment and the routes
+	buf.Reset()
+	printBench(&buf, rr.Results)
+	got, err = readResults(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Results, rr.Results) {
+		t.Errorf("go test format round trip\n%+v\nwant\n%+v", got.Results, rr.Results)
+	}
+
+	buf.Reset()
+	if err := writeCSV(&buf, rr); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(rr.Results)+1 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("CSV has %d records, header %v", len(records), records[0])
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// A route file defines a route set in plain text, a route per line in the
+// canonical syntax:
+//
+//	# Users
+//	GET    /users/:name
+//	DELETE /users/:name
+//	GET    /src/*path     # a catch-all
+//
+// Blank lines and text after a # are ignored. The JSON variant is an object
+// with the name of the suite and the routes. A route may give the values of
+// its parameters in the requests, which are generated otherwise:
+//
+//	{
+//		"suite": "Shop",
+//		"routes": [
+//			{"method": "GET", "path": "/items/:id", "params": {"id": "42"}}
+//		]
+//	}
+//
+// A suite loaded from a text file is named after the file.
+type routeFile struct {
+	Suite  string           `json:"suite,omitempty"`
+	Routes []routeFileEntry `json:"routes"`
+}
+
+type routeFileEntry struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// parseRouteFile parses a route file in either format, telling them apart
+// by the opening brace of JSON.
+func parseRouteFile(data []byte) (*routeFile, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		rf := new(routeFile)
+		if err := json.Unmarshal(data, rf); err != nil {
+			return nil, err
+		}
+		return rf, rf.check(nil)
+	}
+
+	rf := new(routeFile)
+	var lines []int
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 2:
+			rf.Routes = append(rf.Routes, routeFileEntry{Method: fields[0], Path: fields[1]})
+			lines = append(lines, n)
+		default:
+			return nil, fmt.Errorf("line %d: want METHOD /path", n)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return rf, rf.check(lines)
+}
+
+// check checks the routes of rf, naming a route by its line if lines are
+// given.
+func (rf *routeFile) check(lines []int) error {
+	if len(rf.Routes) == 0 {
+		return fmt.Errorf("no routes")
+	}
+	seen := make(map[string]string)
+	for i, e := range rf.Routes {
+		where := fmt.Sprintf("route %d", i+1)
+		if lines != nil {
+			where = fmt.Sprintf("line %d", lines[i])
+		}
+		r := route{e.Method, e.Path}
+		if err := checkRoute(r); err != nil {
+			return fmt.Errorf("%s: %v", where, err)
+		}
+		if other, ok := seen[r.key()]; ok {
+			return fmt.Errorf("%s: %s %s is the same route as %s", where, e.Method, e.Path, other)
+		}
+		seen[r.key()] = e.Path
+
+		names := make(map[string]segmentKind)
+		for _, seg := range r.segments() {
+			names[seg.name] = seg.kind
+		}
+		for name, value := range e.Params {
+			switch kind, ok := names[name]; {
+			case !ok || kind == staticSegment:
+				return fmt.Errorf("%s: no parameter %s in %s", where, name, e.Path)
+			case value == "" || kind == paramSegment && strings.Contains(value, "/"):
+				return fmt.Errorf("%s: invalid value %q of parameter %s", where, value, name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRoute checks that r is a route in the canonical syntax, with one of
+// the methods of methodCaps.
+func checkRoute(r route) error {
+	if _, ok := methodCaps[r.method]; !ok {
+		return fmt.Errorf("invalid method %q", r.method)
+	}
+	_, err := parsePattern(r.path)
+	return err
+}
+
+// routes returns the routes of rf and their requests, with the parameter
+// values rf gives.
+func (rf *routeFile) routes() ([]route, []request) {
+	routes := make([]route, len(rf.Routes))
+	for i, e := range rf.Routes {
+		routes[i] = route{e.Method, e.Path}
+	}
+	requests := genRequests(routes, requestSeed)
+	for i, e := range rf.Routes {
+		if len(e.Params) == 0 {
+			continue
+		}
+		req := &requests[i]
+		for name, v := range e.Params {
+			req.params[name] = v
+		}
+		req.path = requestPath(routes[i], req.params)
+	}
+	return routes, requests
+}
+
+// suiteSources are the flags of the commands selecting suites by name, which
+// load further suites from files or generate them.
+type suiteSources struct {
+	openAPI *string
+	routes  *string
+	synth   synthFlag
+}
+
+func newSuiteSources(flags *flag.FlagSet) *suiteSources {
+	ss := &suiteSources{
+		openAPI: flags.String("openapi", "", "comma-separated OpenAPI 2 or 3 JSON `files` to load as suites"),
+		routes:  flags.String("routes", "", "comma-separated route `files` to load as suites"),
+	}
+	flags.Var(&ss.synth, "synth", "generate a suite of the `shape` key=value,..., may be repeated")
+	return ss
+}
+
+// register registers the suites of the sources.
+func (ss *suiteSources) register() error {
+	if err := registerOpenAPI(*ss.openAPI); err != nil {
+		return err
+	}
+	if err := registerRouteFiles(*ss.routes); err != nil {
+		return err
+	}
+	return registerSynth(ss.synth)
+}
+
+// registerRouteFiles registers a suite for each of the comma-separated route
+// files.
+func registerRouteFiles(files string) error {
+	for _, file := range splitList(files) {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rf, err := parseRouteFile(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		title := rf.Suite
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		routes, requests := rf.routes()
+		s, err := newRouteSuite(title, routes, requests)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		registerSuite(s)
+	}
+	return nil
+}
+
+// newRouteSuite returns the suite of routes loaded from a file or generated,
+// with their requests, or generated requests if nil. Its name is the title
+// in camel case, ending in API. Besides benchmarking all routes, it
+// benchmarks the first static and the first parameterized GET route, if
+// there are any.
+func newRouteSuite(title string, routes []route, requests []request) (*suite, error) {
+	var b strings.Builder
+	word := false
+	for _, r := range title {
+		if r >= unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			word = false
+			continue
+		}
+		if !word {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+		word = true
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		return nil, fmt.Errorf("no suite name in title %q", title)
+	}
+	if !strings.HasSuffix(name, "API") {
+		name += "API"
+	}
+	if findSuite(name) != nil {
+		return nil, fmt.Errorf("suite %s already exists", name)
+	}
+	if requests == nil {
+		requests = genRequests(routes, requestSeed)
+	}
+
+	s := &suite{name: name, routes: routes, requests: requests}
+	prefix := strings.TrimSuffix(name, "API")
+	var static, param string
+	for i, r := range routes {
+		if r.method != "GET" {
+			continue
+		}
+		if r.needs()&(CapParams|CapCatchAll) == 0 {
+			if static == "" {
+				static = requests[i].path
+			}
+		} else if param == "" {
+			param = requests[i].path
+		}
+	}
+	if static != "" {
+		s.benchmarks = append(s.benchmarks, benchmark{prefix + "Static", static})
+	}
+	if param != "" {
+		s.benchmarks = append(s.benchmarks, benchmark{prefix + "Param", param})
+	}
+	s.benchmarks = append(s.benchmarks, benchmark{prefix + "All", ""})
+	return s, nil
+}
+
+func exportCommand(args []string) error {
+	flags := newFlagSet("export", "")
+	var (
+		suiteName = flags.String("suite", "GithubAPI", "the suite to export")
+		format    = flags.String("format", "text", "route file format: text or json")
+		sources   = newSuiteSources(flags)
+	)
+	flags.Parse(args)
+	if err := sources.register(); err != nil {
+		return err
+	}
+
+	s := findSuite(*suiteName)
+	if s == nil {
+		return fmt.Errorf("unknown suite %q, see -list", *suiteName)
+	}
+	return exportRoutes(os.Stdout, s, *format)
+}
+
+// exportRoutes writes the route set of s as a route file. The JSON variant
+// gives the parameter values of the requests, which reproduces them when it
+// is loaded.
+func exportRoutes(w io.Writer, s *suite, format string) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "# %s, %d routes\n", s.name, len(s.routes))
+		for _, r := range s.routes {
+			fmt.Fprintf(w, "%-7s %s\n", r.method, r.path)
+		}
+		return nil
+	case "json":
+		rf := routeFile{Suite: s.name}
+		for i, r := range s.routes {
+			e := routeFileEntry{Method: r.method, Path: r.path}
+			if len(s.requests[i].params) > 0 {
+				e.Params = s.requests[i].params
+			}
+			rf.Routes = append(rf.Routes, e)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(rf)
+	}
+	return fmt.Errorf("unknown format %q, want text or json", format)
+}
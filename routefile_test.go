@@ -0,0 +1,150 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRouteFile(t *testing.T) {
+	rf, err := parseRouteFile([]byte(`
+# Items
+GET    /items
+GET    /items/:id   # one item
+DELETE /items/:id
+
+GET /src/*path
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, requests := rf.routes()
+	want := []route{
+		{"GET", "/items"},
+		{"GET", "/items/:id"},
+		{"DELETE", "/items/:id"},
+		{"GET", "/src/*path"},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("routes %v, want %v", routes, want)
+	}
+	if !reflect.DeepEqual(requests, genRequests(want, requestSeed)) {
+		t.Error("requests differ from the generated ones")
+	}
+
+	rf, err = parseRouteFile([]byte(`{"suite": "Shop", "routes": [
+		{"method": "GET", "path": "/items/:id/:size", "params": {"id": "42"}},
+		{"method": "GET", "path": "/src/*path", "params": {"path": "a/b.go"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.Suite != "Shop" {
+		t.Errorf("suite %q", rf.Suite)
+	}
+	_, requests = rf.routes()
+	if got, want := requests[0].path, "/items/42/"+requests[0].params["size"]; got != want || requests[0].params["id"] != "42" {
+		t.Errorf("request %+v, want path %s", requests[0], want)
+	}
+	if got := requests[1].path; got != "/src/a/b.go" {
+		t.Errorf("request path %s, want /src/a/b.go", got)
+	}
+}
+
+func TestParseRouteFileErrors(t *testing.T) {
+	tests := []struct {
+		data, err string
+	}{
+		{"# nothing\n", "no routes"},
+		{"GET /a\nGET\n", "line 2: want METHOD /path"},
+		{"GET /a\n\nget /b\n", `line 3: invalid method "get"`},
+		{"PURGE /a\n", `line 1: invalid method "PURGE"`},
+		{"GET a\n", `line 1: path "a" does not start with /`},
+		{"GET /src/*path/raw\n", "line 1: catch-all path is not the last segment of /src/*path/raw"},
+		{"GET /a/:\n", "line 1: unnamed parameter in /a/:"},
+		{"GET /a/:id/:id\n", "line 1: parameter id repeated in /a/:id/:id"},
+		{"GET /a/:id\nGET /a/:name\n", "line 2: GET /a/:name is the same route as /a/:id"},
+		{`{"routes": [{"method": "GET", "path": "/a/:id", "params": {"name": "x"}}]}`, "route 1: no parameter name in /a/:id"},
+		{`{"routes": [{"method": "GET", "path": "/a/:id", "params": {"id": "x/y"}}]}`, `route 1: invalid value "x/y" of parameter id`},
+	}
+	for _, test := range tests {
+		_, err := parseRouteFile([]byte(test.data))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: error %v, want %s", test.data, err, test.err)
+		}
+	}
+}
+
+func TestRouteFileMethods(t *testing.T) {
+	rf, err := parseRouteFile([]byte("GET /a/:id\nHEAD /a/:id\nOPTIONS /b\nTRACE /b\nPATCH /a/:id\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes, requests := rf.routes()
+	testLoadRouters(t, routes, requests)
+}
+
+func TestExportRoutes(t *testing.T) {
+	for _, s := range []*suite{staticSuite, githubSuite, gplusSuite, parseSuite} {
+		for _, format := range []string{"text", "json"} {
+			var buf bytes.Buffer
+			if err := exportRoutes(&buf, s, format); err != nil {
+				t.Fatal(err)
+			}
+			rf, err := parseRouteFile(buf.Bytes())
+			if err != nil {
+				t.Fatalf("%s %s: %v", s.name, format, err)
+			}
+			routes, requests := rf.routes()
+			if !reflect.DeepEqual(routes, s.routes) {
+				t.Errorf("%s %s: routes differ", s.name, format)
+			}
+			if !reflect.DeepEqual(requests, s.requests) {
+				t.Errorf("%s %s: requests differ", s.name, format)
+			}
+		}
+	}
+	if err := exportRoutes(ioutil.Discard, staticSuite, "yaml"); err == nil {
+		t.Error("exported in an unknown format")
+	}
+}
+
+func TestRegisterRouteFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "my-shop.txt")
+	if err := ioutil.WriteFile(file, []byte("POST /items\nGET /items/:id\nGET /status\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(suites)
+	defer func() { suites = suites[:n] }()
+	if err := registerRouteFiles(file); err != nil {
+		t.Fatal(err)
+	}
+	s := findSuite("myshop")
+	if s == nil || s.name != "MyShopAPI" {
+		t.Fatalf("no suite MyShopAPI in %d suites", len(suites))
+	}
+	want := []benchmark{
+		{"MyShopStatic", "/status"},
+		{"MyShopParam", s.requests[1].path},
+		{"MyShopAll", ""},
+	}
+	if !reflect.DeepEqual(s.benchmarks, want) {
+		t.Errorf("benchmarks %v, want %v", s.benchmarks, want)
+	}
+	if err := registerRouteFiles(file); err == nil {
+		t.Error("registered the suite twice")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+)
+
+// Beego, http://beego.me/
+func init() {
+	beego.BConfig.RunMode = beego.PROD
+	beego.BeeLogger.Close()
+
+	register(beegoRouter{})
+}
+
+// beegoDialect names the catch-all value :splat.
+var beegoDialect = Dialect{Param: ColonParams, CatchAll: StarCatchAll, CatchAllKey: "splat"}
+
+type beegoRouter struct{}
+
+func (beegoRouter) Name() string     { return "Beego" }
+func (beegoRouter) Package() string  { return "github.com/astaxie/beego" }
+func (beegoRouter) Dialect() Dialect { return beegoDialect }
+
+func (beegoRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapHead | CapOptions | CapTrace | CapConflict
+}
+
+func (beegoRouter) New() Mux {
+	return beegoMux{beego.NewControllerRegister()}
+}
+
+type beegoMux struct {
+	app *beego.ControllerRegister
+}
+
+func (m beegoMux) Handle(method, path string, h Handler) {
+	var f beego.FilterFunc = beegoHandler
+	if h != nil {
+		f = func(ctx *context.Context) {
+			ctx.WriteString(h((*beegoParams)(ctx)))
+		}
+	}
+	m.app.AddMethod(method, path, f)
+}
+
+func (m beegoMux) Build() http.Handler {
+	return m.app
+}
+
+func beegoHandler(ctx *context.Context) {}
+
+type beegoParams context.Context
+
+func (ps *beegoParams) Get(name string) string {
+	return ps.Input.Param(":" + name)
+}
@@ -0,0 +1,77 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net/http"
+
+	goji "github.com/zenazn/goji/web"
+)
+
+// Goji, https://github.com/zenazn/goji/
+func init() {
+	register(gojiRouter{})
+}
+
+var gojiDialect = Dialect{Param: ColonParams, CatchAll: StarCatchAll, CatchAllKey: "*"}
+
+type gojiRouter struct{}
+
+func (gojiRouter) Name() string     { return "Goji" }
+func (gojiRouter) Package() string  { return "github.com/zenazn/goji/web" }
+func (gojiRouter) Dialect() Dialect { return gojiDialect }
+
+// Capabilities leaves out CapHead, since a GET route serves the HEAD requests
+// of its path unless a HEAD route was registered before it.
+func (gojiRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapOptions | CapTrace
+}
+
+func (gojiRouter) New() Mux {
+	return gojiMux{goji.New()}
+}
+
+type gojiMux struct {
+	mux *goji.Mux
+}
+
+func (m gojiMux) Handle(method, path string, h Handler) {
+	var f interface{} = httpHandlerFunc
+	if h != nil {
+		f = func(c goji.C, w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, h(gojiParams(c.URLParams)))
+		}
+	}
+	handle, ok := gojiMethods[method]
+	if !ok {
+		panic("Unknown HTTP method: " + method)
+	}
+	handle(m.mux, path, f)
+}
+
+// gojiMethods are the registration functions of goji.Mux by method, since it
+// has none taking the method as an argument.
+var gojiMethods = map[string]func(m *goji.Mux, pattern goji.PatternType, handler goji.HandlerType){
+	"GET":     (*goji.Mux).Get,
+	"HEAD":    (*goji.Mux).Head,
+	"POST":    (*goji.Mux).Post,
+	"PUT":     (*goji.Mux).Put,
+	"PATCH":   (*goji.Mux).Patch,
+	"DELETE":  (*goji.Mux).Delete,
+	"CONNECT": (*goji.Mux).Connect,
+	"OPTIONS": (*goji.Mux).Options,
+	"TRACE":   (*goji.Mux).Trace,
+}
+
+func (m gojiMux) Build() http.Handler {
+	return m.mux
+}
+
+type gojiParams map[string]string
+
+func (ps gojiParams) Get(name string) string {
+	return ps[name]
+}
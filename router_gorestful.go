@@ -0,0 +1,61 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+)
+
+// go-restful, https://github.com/emicklei/go-restful
+func init() {
+	register(goRestfulRouter{})
+}
+
+var goRestfulDialect = Dialect{Param: BraceParams, CatchAll: BraceStarCatchAll}
+
+type goRestfulRouter struct{}
+
+func (goRestfulRouter) Name() string     { return "GoRestful" }
+func (goRestfulRouter) Package() string  { return "github.com/emicklei/go-restful" }
+func (goRestfulRouter) Dialect() Dialect { return goRestfulDialect }
+
+func (goRestfulRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapHead | CapOptions | CapTrace | CapConflict
+}
+
+func (goRestfulRouter) New() Mux {
+	return goRestfulMux{new(restful.WebService)}
+}
+
+type goRestfulMux struct {
+	ws *restful.WebService
+}
+
+func (m goRestfulMux) Handle(method, path string, h Handler) {
+	var f restful.RouteFunction = goRestfulHandler
+	if h != nil {
+		f = func(r *restful.Request, w *restful.Response) {
+			io.WriteString(w, h((*goRestfulParams)(r)))
+		}
+	}
+	m.ws.Route(m.ws.Method(method).Path(path).To(f))
+}
+
+func (m goRestfulMux) Build() http.Handler {
+	wsContainer := restful.NewContainer()
+	wsContainer.Add(m.ws)
+	return wsContainer
+}
+
+func goRestfulHandler(r *restful.Request, w *restful.Response) {}
+
+type goRestfulParams restful.Request
+
+func (ps *goRestfulParams) Get(name string) string {
+	return (*restful.Request)(ps).PathParameter(name)
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Gorilla Mux, http://www.gorillatoolkit.org/pkg/mux
+func init() {
+	register(gorillaMuxRouter{})
+}
+
+// gorillaMuxDialect matches the rest of the path with a regular expression.
+var gorillaMuxDialect = Dialect{Param: BraceParams, CatchAll: RegexpCatchAll}
+
+type gorillaMuxRouter struct{}
+
+func (gorillaMuxRouter) Name() string     { return "GorillaMux" }
+func (gorillaMuxRouter) Package() string  { return "github.com/gorilla/mux" }
+func (gorillaMuxRouter) Dialect() Dialect { return gorillaMuxDialect }
+
+func (gorillaMuxRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapHead | CapOptions | CapTrace
+}
+
+func (gorillaMuxRouter) New() Mux {
+	return gorillaMux{mux.NewRouter()}
+}
+
+type gorillaMux struct {
+	m *mux.Router
+}
+
+func (m gorillaMux) Handle(method, path string, h Handler) {
+	f := httpHandlerFunc
+	if h != nil {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, h(gorillaParams(mux.Vars(r))))
+		}
+	}
+	m.m.HandleFunc(path, f).Methods(method)
+}
+
+func (m gorillaMux) Build() http.Handler {
+	return m.m
+}
+
+type gorillaParams map[string]string
+
+func (ps gorillaParams) Get(name string) string {
+	return ps[name]
+}
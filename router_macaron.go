@@ -0,0 +1,72 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+
+	"gopkg.in/macaron.v1"
+)
+
+// Macaron, https://github.com/Unknwon/macaron
+func init() {
+	register(macaronRouter{})
+}
+
+// macaronDialect ends a parameter name at the first character other than a
+// letter or digit, so /:client_id is a parameter named client.
+var macaronDialect = Dialect{Param: ColonParams, CatchAll: StarCatchAll, CatchAllKey: "*", AlnumNames: true}
+
+type macaronRouter struct{}
+
+func (macaronRouter) Name() string     { return "Macaron" }
+func (macaronRouter) Package() string  { return "gopkg.in/macaron.v1" }
+func (macaronRouter) Dialect() Dialect { return macaronDialect }
+
+func (macaronRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapHead | CapOptions | CapConflict
+}
+
+func (macaronRouter) New() Mux {
+	return macaronMux{macaron.New()}
+}
+
+type macaronMux struct {
+	m *macaron.Macaron
+}
+
+// Handle registers Martini's no-op handler, which Macaron's route sets have
+// always been benchmarked with.
+func (m macaronMux) Handle(method, path string, h Handler) {
+	var f macaron.Handler = martiniHandler
+	if h != nil {
+		f = func(c *macaron.Context) string {
+			return h((*macaronParams)(c))
+		}
+	}
+	m.m.Handle(method, path, []macaron.Handler{f})
+}
+
+// HandleMicro registers Macaron's own no-op handler, which its micro
+// benchmarks have always been run with.
+func (m macaronMux) HandleMicro(method, path string) {
+	m.m.Handle(method, path, []macaron.Handler{macaronHandler})
+}
+
+func (m macaronMux) Build() http.Handler {
+	return m.m
+}
+
+func macaronHandler(_ *macaron.Context) {}
+
+type macaronParams macaron.Context
+
+func (ps *macaronParams) Get(name string) string {
+	// Params only prefixes names longer than one letter with the colon
+	if name != "*" {
+		name = ":" + name
+	}
+	return (*macaron.Context)(ps).Params(name)
+}
@@ -0,0 +1,64 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-martini/martini"
+)
+
+// Martini, https://github.com/go-martini/martini
+func init() {
+	martini.Env = martini.Prod
+
+	register(martiniRouter{})
+}
+
+// martiniDialect uses a glob for catch-alls, whose value is named after its
+// position among the globs of the pattern.
+var martiniDialect = Dialect{Param: ColonParams, CatchAll: GlobCatchAll, CatchAllKey: "_1"}
+
+type martiniRouter struct{}
+
+func (martiniRouter) Name() string     { return "Martini" }
+func (martiniRouter) Package() string  { return "github.com/go-martini/martini" }
+func (martiniRouter) Dialect() Dialect { return martiniDialect }
+
+func (martiniRouter) Capabilities() Capability {
+	return CapParams | CapCatchAll | CapPatch | CapHead | CapOptions | CapTrace
+}
+
+func (martiniRouter) New() Mux {
+	return martiniMux{martini.NewRouter()}
+}
+
+type martiniMux struct {
+	router martini.Router
+}
+
+func (m martiniMux) Handle(method, path string, h Handler) {
+	var f martini.Handler = martiniHandler
+	if h != nil {
+		f = func(ps martini.Params) string {
+			return h(martiniParams(ps))
+		}
+	}
+	m.router.AddRoute(method, path, f)
+}
+
+func (m martiniMux) Build() http.Handler {
+	martini := martini.New()
+	martini.Action(m.router.Handle)
+	return martini
+}
+
+func martiniHandler() {}
+
+type martiniParams martini.Params
+
+func (ps martiniParams) Get(name string) string {
+	return ps[name]
+}
@@ -0,0 +1,46 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+// http.ServeMux, http://golang.org/pkg/net/http/#ServeMux
+//
+// It matches static paths only and ignores the request method.
+func init() {
+	register(serveMuxRouter{})
+}
+
+type serveMuxRouter struct{}
+
+func (serveMuxRouter) Name() string             { return "HttpServeMux" }
+func (serveMuxRouter) Package() string          { return "net/http" }
+func (serveMuxRouter) Dialect() Dialect         { return StaticDialect }
+func (serveMuxRouter) Capabilities() Capability { return 0 }
+
+func (serveMuxRouter) New() Mux {
+	return serveMux{http.NewServeMux()}
+}
+
+type serveMux struct {
+	mux *http.ServeMux
+}
+
+func (m serveMux) Handle(method, path string, h Handler) {
+	f := httpHandlerFunc
+	if h != nil {
+		f = func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, h(noParams{}))
+		}
+	}
+	m.mux.HandleFunc(path, f)
+}
+
+func (m serveMux) Build() http.Handler {
+	return m.mux
+}
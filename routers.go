@@ -6,27 +6,146 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
-	"regexp"
 	"runtime"
-
-	"github.com/astaxie/beego"
-	"github.com/astaxie/beego/context"
-	"github.com/emicklei/go-restful"
-	"github.com/go-martini/martini"
-	"github.com/gorilla/mux"
-	goji "github.com/zenazn/goji/web"
-	"gopkg.in/macaron.v1"
+	"strings"
 )
 
+// route is a route of a route set. Its path is written in the canonical
+// syntax: /user/:name for a named parameter, /src/*path for a catch-all
+// parameter matching the rest of the path. A catch-all must be the last
+// segment.
 type route struct {
 	method string
 	path   string
 }
 
+type segmentKind int
+
+const (
+	staticSegment   segmentKind = iota // users
+	paramSegment                       // :user
+	catchAllSegment                    // *path
+)
+
+// segment is a path segment of a route.
+type segment struct {
+	kind segmentKind
+
+	// name is the parameter name, or the segment itself if it is static.
+	name string
+}
+
+// segments splits the path of r into its segments, leaving out the empty
+// one before the leading slash.
+func (r route) segments() []segment {
+	parts := strings.Split(r.path, "/")[1:]
+	segs := make([]segment, len(parts))
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			segs[i] = segment{paramSegment, part[1:]}
+		case strings.HasPrefix(part, "*"):
+			segs[i] = segment{catchAllSegment, part[1:]}
+		default:
+			segs[i] = segment{staticSegment, part}
+		}
+	}
+	return segs
+}
+
+// key identifies the route regardless of its parameter names.
+func (r route) key() string {
+	var b strings.Builder
+	b.WriteString(r.method)
+	b.WriteByte(' ')
+	for _, seg := range r.segments() {
+		b.WriteByte('/')
+		switch seg.kind {
+		case paramSegment:
+			b.WriteByte(':')
+		case catchAllSegment:
+			b.WriteByte('*')
+		default:
+			b.WriteString(seg.name)
+		}
+	}
+	return b.String()
+}
+
+// needs returns the capabilities a router must have to load r on its own.
+// See routeNeeds for the ones depending on the other routes of a set.
+func (r route) needs() Capability {
+	caps := methodCaps[r.method]
+	for _, seg := range r.segments() {
+		switch seg.kind {
+		case paramSegment:
+			caps |= CapParams
+		case catchAllSegment:
+			caps |= CapCatchAll
+		}
+	}
+	return caps
+}
+
+// routeNeeds returns the capabilities a router must have to load each of the
+// routes as part of the set.
+//
+// Wherever routes have a static segment at a position others have a
+// parameter at, following the same prefix, the routes on the side with fewer
+// routes need CapConflict. Leaving them out resolves the conflict for
+// routers lacking it, while keeping as many routes as possible.
+func routeNeeds(routes []route) []Capability {
+	type node struct {
+		static, param []int
+	}
+	nodes := make(map[string]*node)
+	var prefixes []string
+
+	for i, route := range routes {
+		prefix := ""
+		for _, seg := range route.segments() {
+			n, ok := nodes[prefix]
+			if !ok {
+				n = new(node)
+				nodes[prefix] = n
+				prefixes = append(prefixes, prefix)
+			}
+			switch seg.kind {
+			case staticSegment:
+				n.static = append(n.static, i)
+				prefix += "/" + seg.name
+			case paramSegment:
+				n.param = append(n.param, i)
+				prefix += "/:"
+			case catchAllSegment:
+				n.param = append(n.param, i)
+				prefix += "/*"
+			}
+		}
+	}
+
+	needs := make([]Capability, len(routes))
+	for i, route := range routes {
+		needs[i] = route.needs()
+	}
+	for _, prefix := range prefixes {
+		n := nodes[prefix]
+		if len(n.static) == 0 || len(n.param) == 0 {
+			continue
+		}
+		side := n.static
+		if len(n.param) < len(n.static) {
+			side = n.param
+		}
+		for _, i := range side {
+			needs[i] |= CapConflict
+		}
+	}
+	return needs
+}
+
 type mockResponseWriter struct{}
 
 func (m *mockResponseWriter) Header() (h http.Header) {
@@ -47,300 +166,206 @@ var nullLogger *log.Logger
 
 func init() {
 	// beego sets it to runtime.NumCPU()
-	// Currently none of the contestors does concurrent routing
+	// The serial benchmarks route on a single thread; the parallel ones
+	// raise it.
 	runtime.GOMAXPROCS(1)
 
 	// makes logging 'webscale' (ignores them)
 	log.SetOutput(new(mockResponseWriter))
 	nullLogger = log.New(new(mockResponseWriter), "", 0)
-
-	initBeego()
-	initMartini()
 }
 
-// Common
-func httpHandlerFunc(w http.ResponseWriter, r *http.Request) {}
+// Router adapts a router or framework to the benchmark. Each router lives in
+// a router_*.go file of its own and registers itself in an init function.
+type Router interface {
+	// Name identifies the router in benchmark names, e.g. "GorillaMux".
+	Name() string
 
-// beego
-func beegoHandler(ctx *context.Context) {}
+	// Package is the import path of the router, which identifies its module
+	// and version in the build info.
+	Package() string
 
-func beegoHandlerWrite(ctx *context.Context) {
-	ctx.WriteString(ctx.Input.Param(":name"))
-}
+	// Dialect is the route pattern syntax the router understands.
+	Dialect() Dialect
 
-func initBeego() {
-	beego.BConfig.RunMode = beego.PROD
-	beego.BeeLogger.Close()
-}
+	// Capabilities reports the routing features the router supports.
+	Capabilities() Capability
 
-func loadBeego(routes []route) http.Handler {
-	re := regexp.MustCompile(":([^/]*)")
-	app := beego.NewControllerRegister()
-	for _, route := range routes {
-		route.path = re.ReplaceAllString(route.path, ":$1")
-		switch route.method {
-		case "GET":
-			app.Get(route.path, beegoHandler)
-		case "POST":
-			app.Post(route.path, beegoHandler)
-		case "PUT":
-			app.Put(route.path, beegoHandler)
-		case "PATCH":
-			app.Patch(route.path, beegoHandler)
-		case "DELETE":
-			app.Delete(route.path, beegoHandler)
-		default:
-			panic("Unknow HTTP method: " + route.method)
-		}
-	}
-	return app
+	// New returns an empty routing structure.
+	New() Mux
 }
 
-func loadBeegoSingle(method, path string, handler beego.FilterFunc) http.Handler {
-	app := beego.NewControllerRegister()
-	switch method {
-	case "GET":
-		app.Get(path, handler)
-	case "POST":
-		app.Post(path, handler)
-	case "PUT":
-		app.Put(path, handler)
-	case "PATCH":
-		app.Patch(path, handler)
-	case "DELETE":
-		app.Delete(path, handler)
-	default:
-		panic("Unknow HTTP method: " + method)
-	}
-	return app
-}
+// Mux is a routing structure under construction.
+type Mux interface {
+	// Handle registers h for method and path. The path is given in the
+	// router's dialect. A nil h registers the router's no-op handler.
+	Handle(method, path string, h Handler)
 
-// goji
-func gojiFuncWrite(c goji.C, w http.ResponseWriter, r *http.Request) {
-	io.WriteString(w, c.URLParams["name"])
+	// Build returns the finished routing structure.
+	Build() http.Handler
 }
 
-func loadGoji(routes []route) http.Handler {
-	mux := goji.New()
-	for _, route := range routes {
-		switch route.method {
-		case "GET":
-			mux.Get(route.path, httpHandlerFunc)
-		case "POST":
-			mux.Post(route.path, httpHandlerFunc)
-		case "PUT":
-			mux.Put(route.path, httpHandlerFunc)
-		case "PATCH":
-			mux.Patch(route.path, httpHandlerFunc)
-		case "DELETE":
-			mux.Delete(route.path, httpHandlerFunc)
-		default:
-			panic("Unknown HTTP method: " + route.method)
-		}
-	}
-	return mux
+// microMux is implemented by the Muxes of routers whose micro benchmarks
+// register another no-op handler than their route sets.
+type microMux interface {
+	// HandleMicro registers the no-op handler of the micro benchmarks for
+	// method and path.
+	HandleMicro(method, path string)
 }
 
-func loadGojiSingle(method, path string, handler interface{}) http.Handler {
-	mux := goji.New()
-	switch method {
-	case "GET":
-		mux.Get(path, handler)
-	case "POST":
-		mux.Post(path, handler)
-	case "PUT":
-		mux.Put(path, handler)
-	case "PATCH":
-		mux.Patch(path, handler)
-	case "DELETE":
-		mux.Delete(path, handler)
-	default:
-		panic("Unknow HTTP method: " + method)
-	}
-	return mux
-}
+// Handler is a router independent handler. It reads path parameters through
+// the param getter of the router that matched the request and returns the
+// response body, which the adapter writes the way the router's own handlers
+// do, so that the benchmarks measure the handler shape of each router.
+type Handler func(ps Params) string
 
-// go-restful
-func goRestfulHandlerWrite(r *restful.Request, w *restful.Response) {
-	io.WriteString(w, r.Request.URL.Query().Get("name"))
+// Params gives access to the path parameters of a matched route.
+type Params interface {
+	Get(name string) string
 }
 
-func goRestfulHandler(r *restful.Request, w *restful.Response) {}
+// Capability is a set of routing features.
+type Capability uint
 
-func loadGoRestful(routes []route) http.Handler {
-	wsContainer := restful.NewContainer()
-	ws := new(restful.WebService)
+const (
+	// CapParams is set for routers supporting named parameters,
+	// e.g. /user/:name.
+	CapParams Capability = 1 << iota
 
-	for _, route := range routes {
-		switch route.method {
-		case "GET":
-			ws.Route(ws.GET(route.path).To(goRestfulHandler))
-		case "POST":
-			ws.Route(ws.POST(route.path).To(goRestfulHandler))
-		case "PUT":
-			ws.Route(ws.PUT(route.path).To(goRestfulHandler))
-		case "PATCH":
-			ws.Route(ws.PATCH(route.path).To(goRestfulHandler))
-		case "DELETE":
-			ws.Route(ws.DELETE(route.path).To(goRestfulHandler))
-		default:
-			panic("Unknow HTTP method: " + route.method)
-		}
-	}
-	wsContainer.Add(ws)
-	return wsContainer
-}
+	// CapCatchAll is set for routers supporting catch-all parameters,
+	// e.g. /src/*path.
+	CapCatchAll
 
-func loadGoRestfulSingle(method, path string, handler restful.RouteFunction) http.Handler {
-	wsContainer := restful.NewContainer()
-	ws := new(restful.WebService)
-	switch method {
-	case "GET":
-		ws.Route(ws.GET(path).To(handler))
-	case "POST":
-		ws.Route(ws.POST(path).To(handler))
-	case "PUT":
-		ws.Route(ws.PUT(path).To(handler))
-	case "PATCH":
-		ws.Route(ws.PATCH(path).To(handler))
-	case "DELETE":
-		ws.Route(ws.DELETE(path).To(handler))
-	default:
-		panic("Unknow HTTP method: " + method)
-	}
-	wsContainer.Add(ws)
-	return wsContainer
-}
+	// CapPatch is set for routers supporting the PATCH method.
+	CapPatch
+
+	// CapHead is set for routers supporting the HEAD method, routing HEAD
+	// requests to the HEAD route of a path rather than to its GET route.
+	CapHead
 
-// gorilla/mux
-func gorillaHandlerWrite(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	io.WriteString(w, params["name"])
+	// CapOptions is set for routers supporting the OPTIONS method.
+	CapOptions
+
+	// CapTrace is set for routers supporting the TRACE method.
+	CapTrace
+
+	// CapConflict is set for routers able to route to a static segment and
+	// a parameter at the same position, e.g. /gists/public and /gists/:id,
+	// regardless of the order they were registered in.
+	CapConflict
+)
+
+// capNames names the capabilities in reports.
+var capNames = []struct {
+	c    Capability
+	name string
+}{
+	{CapParams, "params"},
+	{CapCatchAll, "catch-all"},
+	{CapPatch, "PATCH"},
+	{CapHead, "HEAD"},
+	{CapOptions, "OPTIONS"},
+	{CapTrace, "TRACE"},
+	{CapConflict, "conflicts"},
 }
 
-func loadGorillaMux(routes []route) http.Handler {
-	re := regexp.MustCompile(":([^/]*)")
-	m := mux.NewRouter()
-	for _, route := range routes {
-		m.HandleFunc(
-			re.ReplaceAllString(route.path, "{$1}"),
-			httpHandlerFunc,
-		).Methods(route.method)
-	}
-	return m
+// methodCaps are the methods of routes with the capability a router needs
+// for each. Routes have no other methods.
+var methodCaps = map[string]Capability{
+	"GET":     0,
+	"POST":    0,
+	"PUT":     0,
+	"DELETE":  0,
+	"PATCH":   CapPatch,
+	"HEAD":    CapHead,
+	"OPTIONS": CapOptions,
+	"TRACE":   CapTrace,
 }
 
-func loadGorillaMuxSingle(method, path string, handler http.HandlerFunc) http.Handler {
-	m := mux.NewRouter()
-	m.HandleFunc(path, handler).Methods(method)
-	return m
+func (c Capability) String() string {
+	var names []string
+	for _, cn := range capNames {
+		if c&cn.c != 0 {
+			names = append(names, cn.name)
+		}
+	}
+	return strings.Join(names, ", ")
 }
 
-// Macaron
-func macaronHandler(_ *macaron.Context) {}
+// routers is the registry of all benchmarked routers in registration order.
+var routers []Router
 
-func macaronHandlerWrite(c *macaron.Context) string {
-	return c.Params("name")
+func register(r Router) {
+	routers = append(routers, r)
 }
 
-func loadMacaron(routes []route) http.Handler {
-	m := macaron.New()
+// supports reports whether r is able to take part in benchmarks with routes.
+// Routers lacking parameter support can not, other capabilities only decide
+// which of the routes a router loads; see filterRoutes.
+func supports(r Router, routes []route) bool {
 	for _, route := range routes {
-		switch route.method {
-		case "GET":
-			m.Get(route.path, martiniHandler)
-		case "POST":
-			m.Post(route.path, martiniHandler)
-		case "PUT":
-			m.Put(route.path, martiniHandler)
-		case "PATCH":
-			m.Patch(route.path, martiniHandler)
-		case "DELETE":
-			m.Delete(route.path, martiniHandler)
-		default:
-			panic("Unknow HTTP method: " + route.method)
+		if route.needs()&CapParams != 0 && r.Capabilities()&CapParams == 0 {
+			return false
 		}
 	}
-	return m
+	return true
 }
 
-func loadMacaronSingle(method, path string, handler interface{}) http.Handler {
-	m := macaron.New()
-	switch method {
-	case "GET":
-		m.Get(path, handler)
-	case "POST":
-		m.Post(path, handler)
-	case "PUT":
-		m.Put(path, handler)
-	case "PATCH":
-		m.Patch(path, handler)
-	case "DELETE":
-		m.Delete(path, handler)
-	default:
-		panic("Unknow HTTP method: " + method)
+// filterRoutes returns the indexes of the routes r is able to load and those
+// of the routes it is not, for lack of a capability or since its dialect can
+// not express them.
+func filterRoutes(r Router, routes []route) (loaded, excluded []int) {
+	caps, d := r.Capabilities(), r.Dialect()
+	for i, needs := range routeNeeds(routes) {
+		if _, err := d.pattern(routes[i].path); needs&^caps != 0 || err != nil {
+			excluded = append(excluded, i)
+		} else {
+			loaded = append(loaded, i)
+		}
 	}
-	return m
+	return loaded, excluded
 }
 
-// Martini
-func martiniHandler() {}
-
-func martiniHandlerWrite(params martini.Params) string {
-	return params["name"]
+// load builds the routing structure of r for routes with handler h, or with
+// no-op handlers if h is nil. The routes must be ones filterRoutes lets r
+// load.
+func load(r Router, routes []route, h Handler) http.Handler {
+	return loadWith(r, routes, func(mux Mux, method, path string) {
+		mux.Handle(method, path, h)
+	})
 }
 
-func initMartini() {
-	martini.Env = martini.Prod
+// loadMicro is load for the micro benchmarks, which register the no-op
+// handler of a microMux.
+func loadMicro(r Router, routes []route, h Handler) http.Handler {
+	return loadWith(r, routes, func(mux Mux, method, path string) {
+		if mm, ok := mux.(microMux); ok && h == nil {
+			mm.HandleMicro(method, path)
+		} else {
+			mux.Handle(method, path, h)
+		}
+	})
 }
 
-func loadMartini(routes []route) http.Handler {
-	router := martini.NewRouter()
+// loadWith builds the routing structure of r for routes, registering each
+// of them with handle.
+func loadWith(r Router, routes []route, handle func(mux Mux, method, path string)) http.Handler {
+	mux := r.New()
+	d := r.Dialect()
 	for _, route := range routes {
-		switch route.method {
-		case "GET":
-			router.Get(route.path, martiniHandler)
-		case "POST":
-			router.Post(route.path, martiniHandler)
-		case "PUT":
-			router.Put(route.path, martiniHandler)
-		case "PATCH":
-			router.Patch(route.path, martiniHandler)
-		case "DELETE":
-			router.Delete(route.path, martiniHandler)
-		default:
-			panic("Unknow HTTP method: " + route.method)
+		path, err := d.pattern(route.path)
+		if err != nil {
+			panic(fmt.Sprintf("%s: %v", r.Name(), err))
 		}
+		handle(mux, route.method, path)
 	}
-	martini := martini.New()
-	martini.Action(router.Handle)
-	return martini
+	return mux.Build()
 }
 
-func loadMartiniSingle(method, path string, handler interface{}) http.Handler {
-	router := martini.NewRouter()
-	switch method {
-	case "GET":
-		router.Get(path, handler)
-	case "POST":
-		router.Post(path, handler)
-	case "PUT":
-		router.Put(path, handler)
-	case "PATCH":
-		router.Patch(path, handler)
-	case "DELETE":
-		router.Delete(path, handler)
-	default:
-		panic("Unknow HTTP method: " + method)
-	}
+// Common
+func httpHandlerFunc(w http.ResponseWriter, r *http.Request) {}
 
-	martini := martini.New()
-	martini.Action(router.Handle)
-	return martini
-}
+// noParams is the param getter of routers without parameter support.
+type noParams struct{}
 
-// Usage notice
-func main() {
-	fmt.Println("Usage: go test -bench=. -timeout=20m")
-	os.Exit(1)
-}
+func (noParams) Get(name string) string { return "" }
@@ -0,0 +1,88 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestRouteNeeds(t *testing.T) {
+	routes := []route{
+		{"GET", "/gists"},
+		{"GET", "/gists/public"},
+		{"GET", "/gists/:id"},
+		{"PATCH", "/gists/:id"},
+		{"GET", "/repos/:owner/:repo/contents/*path"},
+		{"GET", "/repos/:owner/:repo/events"},
+		{"GET", "/repos/:owner/:repo/keys"},
+		{"GET", "/repos/:owner/:repo/:archive_format/:ref"},
+	}
+	want := []Capability{
+		0,
+		CapConflict,
+		CapParams,
+		CapParams | CapPatch,
+		CapParams | CapCatchAll,
+		CapParams,
+		CapParams,
+		CapParams | CapConflict,
+	}
+	for i, needs := range routeNeeds(routes) {
+		if needs != want[i] {
+			t.Errorf("%s %s needs %s, want %s", routes[i].method, routes[i].path, needs, want[i])
+		}
+	}
+}
+
+func TestDialectPattern(t *testing.T) {
+	path := "/repos/:owner/:repo/contents/*path"
+	tests := []struct {
+		d    Dialect
+		want string
+	}{
+		{ColonDialect, "/repos/:owner/:repo/contents/*path"},
+		{gorillaMuxDialect, "/repos/{owner}/{repo}/contents/{path:.*}"},
+		{martiniDialect, "/repos/:owner/:repo/contents/**"},
+		{beegoDialect, "/repos/:owner/:repo/contents/*"},
+		{goRestfulDialect, "/repos/{owner}/{repo}/contents/{path:*}"},
+	}
+	for _, test := range tests {
+		got, err := test.d.pattern(path)
+		if err != nil || got != test.want {
+			t.Errorf("pattern is %q, %v, want %q", got, err, test.want)
+		}
+	}
+	if got, err := StaticDialect.pattern("/user/repos/"); err != nil || got != "/user/repos/" {
+		t.Errorf("static pattern is %q, %v", got, err)
+	}
+}
+
+// testLoadRouters loads the routes each router is able to into it and
+// verifies its routing of them.
+func testLoadRouters(t *testing.T, routes []route, requests []request) {
+	for _, r := range routers {
+		if !supports(r, routes) {
+			continue
+		}
+		loaded, _ := filterRoutes(r, routes)
+		var rs []route
+		var reqs []request
+		for _, i := range loaded {
+			rs = append(rs, routes[i])
+			reqs = append(reqs, requests[i])
+		}
+		func() {
+			defer func() {
+				if err := recover(); err != nil {
+					t.Errorf("%s panicked loading %v: %v", r.Name(), rs, err)
+				}
+			}()
+			load(r, rs, nil)
+			if err := verify(r, rs, reqs); err != nil {
+				t.Errorf("%s: %v", r.Name(), err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// verifyScaleLimit is the largest route set the scale command verifies the
+// routers with, since verifying routers with linear lookups takes
+// quadratic time.
+const verifyScaleLimit = 1000
+
+func scaleCommand(args []string) error {
+	flags := newFlagSet("scale", "")
+	var (
+		routerList = flags.String("router", "", "comma-separated routers to scale, all if empty")
+		sizeList   = flags.String("sizes", "10,100,1000,10000,50000", "comma-separated numbers of routes")
+		shape      = flags.String("shape", "depth=2-5,fanout=10,params=0.3", "the `shape` of the route sets, see -synth; routes is set by -sizes")
+		duration   = flags.Duration("duration", 50*time.Millisecond, "time lookups and loading for at least `d`")
+		sampleSize = flags.Int("sample", 1000, "time lookups of `n` requests of each route set")
+	)
+	flags.Parse(args)
+
+	cfg, err := newRunConfig("", *routerList, serial.String(), 1)
+	if err != nil {
+		return err
+	}
+	var sizes []int
+	for _, s := range splitList(*sizeList) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || len(sizes) > 0 && n <= sizes[len(sizes)-1] {
+			return fmt.Errorf("invalid -sizes %q, want increasing numbers of routes", *sizeList)
+		}
+		sizes = append(sizes, n)
+	}
+	if len(sizes) == 0 || *sampleSize < 1 {
+		return fmt.Errorf("invalid -sizes %q or -sample %d", *sizeList, *sampleSize)
+	}
+	sh, err := parseSynthShape(*shape)
+	if err != nil {
+		return fmt.Errorf("invalid -shape: %v", err)
+	}
+
+	sh.routes = sizes[len(sizes)-1]
+	routes, requests, err := synthRoutes(sh)
+	if err != nil {
+		return fmt.Errorf("invalid -shape: %v", err)
+	}
+	var scs []*scaling
+	for _, r := range cfg.routers {
+		if !supports(r, routes) {
+			continue
+		}
+		scs = append(scs, measureScaling(r, routes, requests, sizes, *sampleSize, *duration))
+	}
+	printScaling(os.Stdout, sizes, scs)
+	return nil
+}
+
+// scaling is the growth of a router with the number of routes.
+type scaling struct {
+	router string
+	points []scalePoint
+
+	// failed is the routing verification error.
+	failed error
+}
+
+// scalePoint is the measurement of a router at a route set size.
+type scalePoint struct {
+	// routes is the number of routes the router loaded.
+	routes int
+
+	// lookup is the time per request, load the time to load the routes.
+	lookup float64
+	load   float64
+	mem    memStats
+}
+
+// scaleMetrics are the metrics of the growth curves.
+var scaleMetrics = []struct {
+	title string
+	value func(p *scalePoint) float64
+}{
+	{"Lookup, ns/req", func(p *scalePoint) float64 { return p.lookup }},
+	{"Load time, ns", func(p *scalePoint) float64 { return p.load }},
+	{"Memory, bytes", func(p *scalePoint) float64 { return float64(p.mem.retained) }},
+}
+
+// measureScaling measures r with the first routes of the route set at each
+// size, which makes the smaller sets subsets of the larger ones. Routes r is
+// not able to load are left out, and the growth is measured by the routes it
+// loaded. Lookups are timed with a sample of the
+// requests, drawn at random for each size.
+func measureScaling(r Router, routes []route, requests []request, sizes []int, sample int, d time.Duration) *scaling {
+	sc := &scaling{router: r.Name()}
+	for _, n := range sizes {
+		indexes, _ := filterRoutes(r, routes[:n])
+		rs := make([]route, len(indexes))
+		reqs := make([]request, len(indexes))
+		for i, j := range indexes {
+			rs[i], reqs[i] = routes[j], requests[j]
+		}
+		if n <= verifyScaleLimit {
+			if err := verify(r, rs, reqs); err != nil {
+				sc.failed = err
+				return sc
+			}
+		}
+
+		var h http.Handler
+		p := scalePoint{routes: len(rs)}
+		p.mem = measureMem(len(rs), func() { h = load(r, rs, nil) })
+		// the garbage of measuring the memory is not the load's to collect
+		runtime.GC()
+		p.load = timeLoad(r, rs, d)
+		p.lookup = timeLookups(h, sampleRequests(reqs, sample), d)
+		sc.points = append(sc.points, p)
+	}
+	return sc
+}
+
+// timeLoad returns the time to load routes into r, doubling the number of
+// loads until they take at least d.
+func timeLoad(r Router, routes []route, d time.Duration) float64 {
+	for n := 1; ; n *= 2 {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			load(r, routes, nil)
+		}
+		if elapsed := time.Since(start); elapsed >= d || n >= 1<<20 {
+			return float64(elapsed.Nanoseconds()) / float64(n)
+		}
+	}
+}
+
+// sampleRequests returns n requests drawn from requests without
+// replacement, or all of them if there are no more.
+func sampleRequests(requests []request, n int) []request {
+	if len(requests) <= n {
+		return requests
+	}
+	rnd := rand.New(rand.NewSource(requestSeed))
+	sample := make([]request, n)
+	for i, j := range rnd.Perm(len(requests))[:n] {
+		sample[i] = requests[j]
+	}
+	return sample
+}
+
+// timeLookups returns the time per request of router serving requests,
+// doubling the number of passes over them until they take at least d.
+func timeLookups(router http.Handler, requests []request, d time.Duration) float64 {
+	w := new(mockResponseWriter)
+	reqs := make([]*http.Request, len(requests))
+	for i, req := range requests {
+		reqs[i], _ = http.NewRequest(req.method, req.path, nil)
+		reqs[i].RequestURI = reqs[i].URL.RequestURI()
+	}
+	for n := 1; ; n *= 2 {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			for _, r := range reqs {
+				router.ServeHTTP(w, r)
+			}
+		}
+		if elapsed := time.Since(start); elapsed >= d || n >= 1<<30 {
+			return float64(elapsed.Nanoseconds()) / float64(n*len(reqs))
+		}
+	}
+}
+
+// complexities are the models of growth fitted to the measurements, in
+// order of their growth.
+var complexities = []struct {
+	name string
+	f    func(n float64) float64
+}{
+	{"O(1)", func(n float64) float64 { return 0 }},
+	{"O(log n)", func(n float64) float64 { return math.Log(n) }},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log(n) }},
+	{"O(n²)", func(n float64) float64 { return n * n }},
+}
+
+// fitComplexity fits each model t = a + b·f(n), b ≥ 0, to the values ts at
+// the sizes ns by least squares of the relative errors. It returns the
+// slowest growing model whose root mean square relative error is within 10
+// percentage points of the smallest one, which keeps noise from passing for
+// faster growth.
+func fitComplexity(ns, ts []float64) (name string, rmse float64) {
+	errs := make([]float64, len(complexities))
+	min := math.Inf(1)
+	for i, c := range complexities {
+		var w, sx, st, sxx, sxt float64
+		for j, n := range ns {
+			x, t := c.f(n), ts[j]
+			wj := 1 / (t * t)
+			w += wj
+			sx += wj * x
+			st += wj * t
+			sxx += wj * x * x
+			sxt += wj * x * t
+		}
+		var a, b float64
+		if det := w*sxx - sx*sx; det > 0 {
+			b = (w*sxt - sx*st) / det
+		}
+		if b < 0 {
+			b = 0
+		}
+		a = (st - b*sx) / w
+
+		var sum float64
+		for j, n := range ns {
+			e := (ts[j] - a - b*c.f(n)) / ts[j]
+			sum += e * e
+		}
+		errs[i] = math.Sqrt(sum / float64(len(ns)))
+		min = math.Min(min, errs[i])
+	}
+	for i, c := range complexities {
+		if errs[i] <= min+0.1 {
+			return c.name, errs[i]
+		}
+	}
+	return "", 0
+}
+
+// growthExponent returns the exponent k of the power law t ∝ n^k between
+// the two largest sizes, where the growth of the routing structure outweighs
+// the constant cost of serving a request.
+func growthExponent(ns, ts []float64) float64 {
+	i := len(ns) - 1
+	return math.Log(ts[i]/ts[i-1]) / math.Log(ns[i]/ns[i-1])
+}
+
+// printScaling prints a table per metric with the value of each router at
+// each size, its growth exponent and the complexity fitted to it. Sizes at
+// which the router loaded as many routes as at the size before are left out
+// of the fit, and a router with fewer than three distinct ones gets none.
+// Routers failing the routing verification are listed at the end.
+func printScaling(w io.Writer, sizes []int, scs []*scaling) {
+	for _, m := range scaleMetrics {
+		fmt.Fprintf(w, "%s, by routes\n", m.title)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprint(tw, "Router\t")
+		for _, n := range sizes {
+			fmt.Fprintf(tw, "%s\t", formatValue(float64(n)))
+		}
+		fmt.Fprintln(tw, "growth\tfit\t")
+		for _, sc := range scs {
+			if sc.failed != nil {
+				continue
+			}
+			fmt.Fprintf(tw, "%s\t", sc.router)
+			var ns, ts []float64
+			for i := range sc.points {
+				p := &sc.points[i]
+				v := m.value(p)
+				fmt.Fprintf(tw, "%s\t", formatValue(v))
+				if v > 0 && (len(ns) == 0 || float64(p.routes) != ns[len(ns)-1]) {
+					ns = append(ns, float64(p.routes))
+					ts = append(ts, v)
+				}
+			}
+			if len(ns) < 3 {
+				fmt.Fprintln(tw, "-\t-\t")
+				continue
+			}
+			fit, _ := fitComplexity(ns, ts)
+			fmt.Fprintf(tw, "n^%.2f\t%s\t\n", growthExponent(ns, ts), fit)
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+	for _, sc := range scs {
+		if sc.failed != nil {
+			fmt.Fprintf(w, "%s\tFAILED routing verification: %v\n", sc.router, sc.failed)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFitComplexity(t *testing.T) {
+	ns := []float64{10, 100, 1000, 10000, 50000}
+	tests := []struct {
+		name string
+		f    func(n float64) float64
+	}{
+		{"O(1)", func(n float64) float64 { return 300 }},
+		{"O(log n)", func(n float64) float64 { return 100 + 50*math.Log(n) }},
+		{"O(n)", func(n float64) float64 { return 200 + 3*n }},
+		{"O(n²)", func(n float64) float64 { return 1000 + n*n/10 }},
+	}
+	for _, test := range tests {
+		ts := make([]float64, len(ns))
+		for i, n := range ns {
+			// ±5% of noise
+			ts[i] = test.f(n) * (1 + 0.05*float64(i%2*2-1))
+		}
+		if got, _ := fitComplexity(ns, ts); got != test.name {
+			t.Errorf("fitted %s to %v, want %s", got, ts, test.name)
+		}
+	}
+}
+
+func TestGrowthExponent(t *testing.T) {
+	k := growthExponent([]float64{10, 1000, 10000}, []float64{50, 200, 2000})
+	if math.Abs(k-1) > 1e-9 {
+		t.Errorf("exponent %v, want 1", k)
+	}
+}
+
+func TestSampleRequests(t *testing.T) {
+	requests := genRequests(githubAPI, requestSeed)
+	if got := sampleRequests(requests, len(requests)); len(got) != len(requests) {
+		t.Errorf("sampled %d of all %d requests", len(got), len(requests))
+	}
+	sample := sampleRequests(requests, 10)
+	seen := make(map[string]bool)
+	for _, req := range sample {
+		seen[req.method+" "+req.path] = true
+	}
+	if len(sample) != 10 || len(seen) != 10 {
+		t.Errorf("sampled %d requests, %d distinct, want 10", len(sample), len(seen))
+	}
+}
+
+func TestMeasureScaling(t *testing.T) {
+	sh, _ := parseSynthShape("depth=2-3,fanout=5")
+	sh.routes = 100
+	routes, requests, err := synthRoutes(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := []int{10, 30, 100}
+	sc := measureScaling(findRouter("goji"), routes, requests, sizes, 20, time.Millisecond)
+	if sc.failed != nil {
+		t.Fatal(sc.failed)
+	}
+	if len(sc.points) != 3 {
+		t.Fatalf("%d points, want 3", len(sc.points))
+	}
+	for i, p := range sc.points {
+		if p.routes != sizes[i] || p.lookup <= 0 || p.load <= 0 || p.mem.routes != sizes[i] {
+			t.Errorf("point %+v at %d routes", p, sizes[i])
+		}
+	}
+
+	// Goji leaves out the routes of one side of each conflict
+	sh.conflicts, sh.params = 1, 0.5
+	routes, requests, err = synthRoutes(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := measureScaling(findRouter("goji"), routes, requests, []int{100}, 20, time.Millisecond).points[0]
+	if p.routes >= 100 || p.routes != p.mem.routes {
+		t.Errorf("point %+v of 100 routes with conflicts", p)
+	}
+
+	var buf bytes.Buffer
+	printScaling(&buf, sizes, []*scaling{sc, {router: "Broken", failed: errors.New("test")}})
+	out := buf.String()
+	for _, want := range []string{"Lookup, ns/req, by routes", "Load time, ns, by routes", "Memory, bytes, by routes", "Broken\tFAILED routing verification: test"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output\n%s\nlacks %q", out, want)
+		}
+	}
+	if strings.Count(out, "Goji") != 3 {
+		t.Errorf("output\n%s\nlacks a row of Goji per metric", out)
+	}
+}
+
+func TestPrintScalingEqualRoutes(t *testing.T) {
+	// the routes of the last size all need what the router lacks
+	sc := &scaling{router: "Goji", points: []scalePoint{
+		{routes: 10, lookup: 50, load: 1e4},
+		{routes: 30, lookup: 60, load: 3e4},
+		{routes: 30, lookup: 61, load: 3e4},
+	}}
+	var buf bytes.Buffer
+	printScaling(&buf, []int{10, 30, 100}, []*scaling{sc})
+	for _, line := range strings.Split(buf.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "Goji" && strings.Join(fields[4:], " ") != "- -" {
+			t.Errorf("row %q has a growth of two distinct sizes", line)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// microGroup is the score group of the micro suites, which are scored
+// together. Every other suite is a group of its own.
+const microGroup = "micro"
+
+func scoreCommand(args []string) error {
+	flags := newFlagSet("score", "results")
+	baseline := flags.String("baseline", "HttpServeMux/StaticAll",
+		"normalize by `router[/benchmark]`: its ns/req on the benchmark, or on each benchmark if none is given")
+	weights := flags.String("weights", "", "comma-separated `group=weight` of the suites, or micro for the micro suites; 1 if not given")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	sc, err := newScoring(*baseline, *weights)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rr, err := readResults(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	scores, err := sc.score(reportResults(rr.Results))
+	if err != nil {
+		return err
+	}
+	sc.print(os.Stdout, scores)
+	return nil
+}
+
+// scoring normalizes the time per request of the routers against a
+// baseline and scores them by the weighted geometric mean over the groups
+// of suites.
+type scoring struct {
+	// baseline is the router results are normalized by, against its result
+	// of benchmark or, if empty, of the same benchmark.
+	baseline  string
+	benchmark string
+
+	groups  []string
+	weights map[string]float64
+}
+
+// newScoring parses the baseline and the comma-separated group weights.
+func newScoring(baseline, weights string) (*scoring, error) {
+	sc := &scoring{weights: make(map[string]float64)}
+	name := baseline
+	if i := strings.Index(baseline, "/"); i >= 0 {
+		name, sc.benchmark = baseline[:i], baseline[i+1:]
+		if findBenchmarkSuite(sc.benchmark) == nil {
+			return nil, fmt.Errorf("unknown baseline benchmark %q", sc.benchmark)
+		}
+	}
+	r := findRouter(name)
+	if r == nil {
+		return nil, fmt.Errorf("unknown baseline router %q, see -list", name)
+	}
+	sc.baseline = r.Name()
+
+	for _, s := range suites {
+		g := scoreGroup(s)
+		if _, ok := sc.weights[g]; !ok {
+			sc.groups = append(sc.groups, g)
+			sc.weights[g] = 1
+		}
+	}
+	for _, gw := range splitList(weights) {
+		i := strings.Index(gw, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid weight %q, want group=weight", gw)
+		}
+		g := gw[:i]
+		if !strings.EqualFold(g, microGroup) {
+			s := findSuite(g)
+			if s == nil || s.micro {
+				return nil, fmt.Errorf("unknown group %q, want a suite or %s", g, microGroup)
+			}
+			g = s.name
+		} else {
+			g = microGroup
+		}
+		w, err := strconv.ParseFloat(gw[i+1:], 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight %q of %s", gw[i+1:], g)
+		}
+		sc.weights[g] = w
+	}
+	var total float64
+	for _, w := range sc.weights {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("all weights are 0")
+	}
+	return sc, nil
+}
+
+func scoreGroup(s *suite) string {
+	if s.micro {
+		return microGroup
+	}
+	return s.name
+}
+
+// routerScore is the score of a router: the geometric means of its
+// normalized times per request by group, and their weighted geometric mean.
+type routerScore struct {
+	router string
+	groups map[string]float64
+	score  float64
+
+	// weight is the sum of the weights of the groups with results, of all
+	// groups it is complete.
+	weight float64
+}
+
+// score scores the routers with results. A group scores the geometric mean
+// of the normalized ns/req of the benchmarks of its suites, the overall
+// score is the mean of the group scores weighted geometrically. Lower is
+// faster, the baseline scores 1 on its benchmark.
+func (sc *scoring) score(results []result) ([]*routerScore, error) {
+	nsPerReq := func(res *result) float64 {
+		if res.NsPerRequest > 0 {
+			return res.NsPerRequest
+		}
+		// results of former runs lack ns/req
+		if s := findBenchmarkSuite(res.Benchmark); s != nil && res.Routes > 0 {
+			for _, bm := range s.benchmarks {
+				if bm.name == res.Benchmark && bm.path == "" {
+					return res.NsPerOp / float64(res.Routes)
+				}
+			}
+		}
+		return res.NsPerOp
+	}
+
+	base := make(map[string]float64) // by benchmark
+	for i := range results {
+		res := &results[i]
+		if res.Router == sc.baseline && res.Error == "" && findBenchmarkSuite(res.Benchmark) != nil {
+			base[res.Benchmark] = nsPerReq(res)
+		}
+	}
+	if sc.benchmark != "" && base[sc.benchmark] == 0 {
+		return nil, fmt.Errorf("no result of the baseline %s/%s", sc.baseline, sc.benchmark)
+	}
+
+	// the logarithms of the normalized values by router and group
+	logs := make(map[string]map[string][]float64)
+	for i := range results {
+		res := &results[i]
+		s := findBenchmarkSuite(res.Benchmark)
+		if res.Error != "" || s == nil {
+			continue
+		}
+		ref := base[sc.benchmark]
+		if sc.benchmark == "" {
+			ref = base[res.Benchmark]
+		}
+		if ref == 0 {
+			continue
+		}
+		if logs[res.Router] == nil {
+			logs[res.Router] = make(map[string][]float64)
+		}
+		g := scoreGroup(s)
+		logs[res.Router][g] = append(logs[res.Router][g], math.Log(nsPerReq(res)/ref))
+	}
+
+	var scores []*routerScore
+	for _, r := range routers {
+		byGroup := logs[r.Name()]
+		if byGroup == nil {
+			continue
+		}
+		rs := &routerScore{router: r.Name(), groups: make(map[string]float64)}
+		var sum float64
+		for _, g := range sc.groups {
+			ls, w := byGroup[g], sc.weights[g]
+			if len(ls) == 0 {
+				continue
+			}
+			var mean float64
+			for _, l := range ls {
+				mean += l
+			}
+			mean /= float64(len(ls))
+			rs.groups[g] = math.Exp(mean)
+			sum += w * mean
+			rs.weight += w
+		}
+		if rs.weight > 0 {
+			rs.score = math.Exp(sum / rs.weight)
+		}
+		scores = append(scores, rs)
+	}
+	// routers with results of groups weighted 0 only have no score
+	sort.SliceStable(scores, func(i, j int) bool {
+		if (scores[i].weight > 0) != (scores[j].weight > 0) {
+			return scores[i].weight > 0
+		}
+		return scores[i].score < scores[j].score
+	})
+	return scores, nil
+}
+
+// print prints the scores as a table, fastest first. Routers lacking
+// results of weighted groups are scored over the others, which is marked by
+// the share of the weight their score covers.
+func (sc *scoring) print(w io.Writer, scores []*routerScore) {
+	if sc.benchmark != "" {
+		fmt.Fprintf(w, "Normalized ns/req relative to %s on %s, lower is faster.\n\n", sc.baseline, sc.benchmark)
+	} else {
+		fmt.Fprintf(w, "Normalized ns/req relative to %s on each benchmark, lower is faster.\n\n", sc.baseline)
+	}
+	var total float64
+	for _, g := range sc.groups {
+		total += sc.weights[g]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "Router\t")
+	for _, g := range sc.groups {
+		fmt.Fprintf(tw, "%s (×%g)\t", g, sc.weights[g])
+	}
+	fmt.Fprintln(tw, "score\tweight\t")
+	for _, rs := range scores {
+		fmt.Fprintf(tw, "%s\t", rs.router)
+		for _, g := range sc.groups {
+			if v, ok := rs.groups[g]; ok {
+				fmt.Fprintf(tw, "%.2f\t", v)
+			} else {
+				fmt.Fprint(tw, "-\t")
+			}
+		}
+		fmt.Fprintf(tw, "%.2f\t%.0f%%\t\n", rs.score, rs.weight/total*100)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,88 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	results := []result{
+		{Benchmark: "StaticAll", Router: "HttpServeMux", NsPerOp: 3140, NsPerRequest: 20, Routes: 157},
+		{Benchmark: "StaticAll", Router: "Goji", NsPerOp: 6280, NsPerRequest: 40, Routes: 157},
+		// a former result without ns/req, 100 ns per request
+		{Benchmark: "GithubAll", Router: "Goji", NsPerOp: 22500, Routes: 225},
+		// a single request per op
+		{Benchmark: "Param", Router: "Goji", NsPerOp: 200},
+		{Benchmark: "Param", Router: "Broken", Error: "FAILED routing verification"},
+	}
+
+	for _, test := range []struct {
+		weights string
+		goji    float64
+		weight  float64
+	}{
+		// the geometric mean of 2, 5 and 10 over 3 of the 5 groups
+		{"", math.Cbrt(100), 3},
+		{"micro=0", math.Sqrt(10), 2},
+		{"static=2, github=1", math.Pow(2*2*5*10, 1.0/4), 4},
+	} {
+		sc, err := newScoring("httpservemux/StaticAll", test.weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scores, err := sc.score(results)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scores) != 2 || scores[0].router != "HttpServeMux" || scores[1].router != "Goji" {
+			t.Fatalf("weights %q: scored %+v", test.weights, scores)
+		}
+		goji := scores[1]
+		if math.Abs(goji.score-test.goji) > 1e-9 || goji.weight != test.weight {
+			t.Errorf("weights %q: Goji scores %v with weight %v, want %v with %v",
+				test.weights, goji.score, goji.weight, test.goji, test.weight)
+		}
+		if g := goji.groups["GithubAPI"]; math.Abs(g-5) > 1e-9 {
+			t.Errorf("weights %q: Goji GithubAPI group scores %v, want 5", test.weights, g)
+		}
+	}
+
+	sc, _ := newScoring("HttpServeMux/StaticAll", "")
+	scores, _ := sc.score(results)
+	var buf bytes.Buffer
+	sc.print(&buf, scores)
+	if !strings.Contains(strings.Join(strings.Fields(buf.String()), " "), "Goji 5.00 - 10.00 - - 2.00 4.64 50%") {
+		t.Errorf("output\n%s\nlacks the score 4.64 of 50%% of the weight", buf.String())
+	}
+
+	// normalized by each benchmark, Goji has no StaticAll baseline otherwise
+	sc, _ = newScoring("HttpServeMux", "")
+	if scores, _ = sc.score(results); len(scores) != 2 || scores[1].score != 2 {
+		t.Errorf("scored %+v by each benchmark, want Goji 2", scores)
+	}
+
+	for _, bad := range [][2]string{
+		{"Nginx", ""},
+		{"Goji/NoSuchBenchmark", ""},
+		{"Goji", "param=1"},
+		{"Goji", "static"},
+		{"Goji", "static=-1"},
+	} {
+		if _, err := newScoring(bad[0], bad[1]); err == nil {
+			t.Errorf("newScoring(%q, %q) succeeded", bad[0], bad[1])
+		}
+	}
+	if _, err := newScoring("Goji/StaticAll", ""); err != nil {
+		t.Error(err)
+	}
+	sc, _ = newScoring("Goji/GPlusAll", "")
+	if _, err := sc.score(results); err == nil {
+		t.Errorf("scored without a baseline result")
+	}
+}
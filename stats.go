@@ -0,0 +1,181 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// sample is a set of measurements of the same metric.
+type sample []float64
+
+// sorted returns a sorted copy of s.
+func (s sample) sorted() sample {
+	c := append(sample(nil), s...)
+	sort.Float64s(c)
+	return c
+}
+
+// median returns the median of s, 0 if it is empty.
+func (s sample) median() float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	c := s.sorted()
+	if len(c)%2 == 1 {
+		return c[len(c)/2]
+	}
+	return (c[len(c)/2-1] + c[len(c)/2]) / 2
+}
+
+// medianCI returns the distribution-free confidence interval of the median
+// of s, bounded by the order statistics the binomial distribution gives for
+// the confidence level. ok is false if s is too small for the level.
+func (s sample) medianCI(confidence float64) (lo, hi float64, ok bool) {
+	n := len(s)
+	if n == 0 {
+		return 0, 0, false
+	}
+	// find the largest k with P(X < k) <= (1-confidence)/2 for X ~ B(n, 1/2),
+	// the interval is then [x(k), x(n+1-k)] with 1-based order statistics
+	tail := (1 - confidence) / 2
+	k, cdf := 0, 0.0
+	for i := 0; i <= n/2; i++ {
+		cdf += binomial(n, i) / math.Pow(2, float64(n))
+		if cdf > tail {
+			break
+		}
+		k = i + 1
+	}
+	if k == 0 {
+		return 0, 0, false
+	}
+	c := s.sorted()
+	return c[k-1], c[n-k], true
+}
+
+// binomial returns the binomial coefficient n over k.
+func binomial(n, k int) float64 {
+	lg := func(x int) float64 {
+		v, _ := math.Lgamma(float64(x + 1))
+		return v
+	}
+	return math.Round(math.Exp(lg(n) - lg(k) - lg(n-k)))
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of
+// the null hypothesis that x and y come from the same distribution. Samples
+// without ties use the exact distribution of U, others the normal
+// approximation with tie correction.
+func mannWhitneyU(x, y sample) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	// rank the merged samples, ties get the mean of their ranks
+	type value struct {
+		v   float64
+		isX bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, value{v, true})
+	}
+	for _, v := range y {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+	var rankX, tieTerm float64
+	ties := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for _, v := range all[i:j] {
+			if v.isX {
+				rankX += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			ties = true
+			tieTerm += t*t*t - t
+		}
+		i = j
+	}
+	u := rankX - float64(n1*(n1+1))/2
+
+	if !ties && n1*n2 <= 400 {
+		return uExactP(n1, n2, u)
+	}
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * (n + 1 - tieTerm/(n*(n-1)))
+	if variance == 0 {
+		return 1
+	}
+	// continuity correction
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		return 1
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// uExactP returns the two-sided p-value of u in the exact distribution of
+// the U statistic for samples of sizes n1 and n2 without ties.
+func uExactP(n1, n2 int, u float64) float64 {
+	// counts[i][j][v] is the number of orderings of i x and j y values with
+	// U = v, built up by the recurrence on the largest value
+	max := n1 * n2
+	counts := make([][][]float64, n1+1)
+	for i := range counts {
+		counts[i] = make([][]float64, n2+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, max+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+			for v := range counts[i][j] {
+				// the largest value is x, which beats all j y values
+				if v >= j {
+					counts[i][j][v] += counts[i-1][j][v-j]
+				}
+				counts[i][j][v] += counts[i][j-1][v]
+			}
+		}
+	}
+
+	dist := counts[n1][n2]
+	var total, below, above float64
+	for v, c := range dist {
+		total += c
+		if float64(v) <= u {
+			below += c
+		}
+		if float64(v) >= u {
+			above += c
+		}
+	}
+	return math.Min(1, 2*math.Min(below, above)/total)
+}
+
+// percentile returns the p-th percentile of the sorted sample s by the
+// nearest rank, 0 if it is empty.
+func (s sample) percentile(p float64) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	// the tolerance keeps 99.9% of 1000 from rounding up to 1000
+	rank := int(math.Ceil(p/100*float64(len(s)) - 1e-9))
+	if rank < 1 {
+		rank = 1
+	}
+	return s[rank-1]
+}
@@ -0,0 +1,61 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMedianCI(t *testing.T) {
+	s := sample{5, 3, 9, 1, 7, 2, 8, 4, 6, 10}
+	if med := s.median(); med != 5.5 {
+		t.Errorf("median %v, want 5.5", med)
+	}
+	// P(X <= 1) = 11/1024 < 0.025 < P(X <= 2) = 56/1024 for X ~ B(10, 1/2)
+	if lo, hi, ok := s.medianCI(0.95); !ok || lo != 2 || hi != 9 {
+		t.Errorf("95%% interval [%v, %v] %v, want [2, 9]", lo, hi, ok)
+	}
+	// 2 * 1/32 > 0.05, 5 runs are too few for 95%
+	if _, _, ok := s[:5].medianCI(0.95); ok {
+		t.Errorf("95%% interval of 5 values")
+	}
+	if lo, hi, ok := s[:6].medianCI(0.95); !ok || lo != 1 || hi != 9 {
+		t.Errorf("95%% interval [%v, %v] %v of 6 values, want [1, 9]", lo, hi, ok)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	for _, test := range []struct {
+		x, y sample
+		p    float64
+	}{
+		// separated samples, 2 of the C(10, 5) = 252 orderings are as extreme
+		{sample{1, 2, 3, 4, 5}, sample{6, 7, 8, 9, 10}, 2.0 / 252},
+		{sample{6, 7, 8, 9, 10}, sample{1, 2, 3, 4, 5}, 2.0 / 252},
+		// interleaved samples
+		{sample{1, 3, 5, 7, 9}, sample{2, 4, 6, 8, 10}, 0.690476},
+		// identical samples
+		{sample{4, 4, 4}, sample{4, 4, 4}, 1},
+		// ties, by the normal approximation
+		{sample{1, 1, 2, 2, 3}, sample{3, 4, 4, 5, 5}, 0.0146},
+	} {
+		if p := mannWhitneyU(test.x, test.y); math.Abs(p-test.p) > 1e-3 {
+			t.Errorf("p of %v and %v is %.4f, want %.4f", test.x, test.y, p, test.p)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var s sample
+	for i := 1; i <= 1000; i++ {
+		s = append(s, float64(i))
+	}
+	for p, want := range map[float64]float64{0: 1, 50: 500, 90: 900, 99: 990, 99.9: 999, 100: 1000} {
+		if got := s.percentile(p); got != want {
+			t.Errorf("percentile %v = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// synthShape is the shape of a synthetic route set. The routes are paths
+// from the root of a prefix tree: a node has fanOut static children, or a
+// parameter child with the probability params. With the probability
+// conflicts, a parameter child has fanOut-1 static siblings, which routers
+// without CapConflict can not load next to it.
+type synthShape struct {
+	name   string
+	routes int
+
+	// depths are the weights of the path depths, starting at 1.
+	depths []float64
+
+	params    float64
+	conflicts float64
+	fanOut    int
+	methods   []methodWeight
+	seed      int64
+}
+
+type methodWeight struct {
+	method string
+	weight float64
+}
+
+// defaultSynthShape is the shape of synthetic route sets, unless a spec
+// changes it: 1000 routes of depth 2 to 4, a third of them deepest.
+var defaultSynthShape = synthShape{
+	routes:  1000,
+	depths:  []float64{0, 1, 1, 1},
+	params:  0.3,
+	fanOut:  10,
+	methods: []methodWeight{{"GET", 6}, {"POST", 2}, {"PUT", 1}, {"DELETE", 1}},
+	seed:    requestSeed,
+}
+
+// parseSynthShape parses a spec of comma-separated key=value pairs into a
+// shape, starting from the default one:
+//
+//	name=Wide                 the suite name, Synth<routes> if not given
+//	routes=5000               the number of routes
+//	depth=3                   the path depth, or
+//	depth=2-5                 a range of equally likely depths, or
+//	depth=1:1/2:3/3:4         depths with their weights
+//	params=0.6                the probability of a parameter child
+//	conflicts=0.1             the probability of static siblings of a parameter
+//	fanout=20                 the number of static children of a node
+//	methods=GET:6/POST:1      the methods with their weights
+//	seed=1                    the seed of the generator
+func parseSynthShape(spec string) (synthShape, error) {
+	sh := defaultSynthShape
+	for _, kv := range splitList(spec) {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return sh, fmt.Errorf("invalid %q, want key=value", kv)
+		}
+		key, value := kv[:i], kv[i+1:]
+		var err error
+		switch key {
+		case "name":
+			sh.name = value
+		case "routes":
+			sh.routes, err = strconv.Atoi(value)
+			if err == nil && sh.routes < 1 {
+				err = fmt.Errorf("no routes")
+			}
+		case "depth":
+			sh.depths, err = parseDepths(value)
+		case "params":
+			sh.params, err = parseProbability(value)
+		case "conflicts":
+			sh.conflicts, err = parseProbability(value)
+		case "fanout":
+			sh.fanOut, err = strconv.Atoi(value)
+			if err == nil && sh.fanOut < 1 {
+				err = fmt.Errorf("fan-out below 1")
+			}
+		case "methods":
+			sh.methods, err = parseMethods(value)
+		case "seed":
+			sh.seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return sh, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return sh, fmt.Errorf("invalid %s %q: %v", key, value, err)
+		}
+	}
+	if sh.name == "" {
+		sh.name = "Synth" + strconv.Itoa(sh.routes)
+	}
+	return sh, nil
+}
+
+func parseDepths(s string) ([]float64, error) {
+	if i := strings.Index(s, "-"); i >= 0 {
+		min, err1 := strconv.Atoi(s[:i])
+		max, err2 := strconv.Atoi(s[i+1:])
+		if err1 != nil || err2 != nil || min < 1 || max < min {
+			return nil, fmt.Errorf("want min-max")
+		}
+		depths := make([]float64, max)
+		for d := min; d <= max; d++ {
+			depths[d-1] = 1
+		}
+		return depths, nil
+	}
+	if !strings.Contains(s, ":") {
+		d, err := strconv.Atoi(s)
+		if err != nil || d < 1 {
+			return nil, fmt.Errorf("want a depth of at least 1")
+		}
+		depths := make([]float64, d)
+		depths[d-1] = 1
+		return depths, nil
+	}
+	var depths []float64
+	for _, dw := range strings.Split(s, "/") {
+		i := strings.Index(dw, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("want depth:weight")
+		}
+		d, err1 := strconv.Atoi(dw[:i])
+		w, err2 := strconv.ParseFloat(dw[i+1:], 64)
+		if err1 != nil || err2 != nil || d < 1 || w < 0 {
+			return nil, fmt.Errorf("want depth:weight")
+		}
+		for len(depths) < d {
+			depths = append(depths, 0)
+		}
+		depths[d-1] = w
+	}
+	return depths, checkWeights(depths)
+}
+
+func parseMethods(s string) ([]methodWeight, error) {
+	var methods []methodWeight
+	var weights []float64
+	for _, mw := range strings.Split(s, "/") {
+		m := methodWeight{mw, 1}
+		if i := strings.Index(mw, ":"); i >= 0 {
+			w, err := strconv.ParseFloat(mw[i+1:], 64)
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("want method:weight")
+			}
+			m = methodWeight{mw[:i], w}
+		}
+		if err := checkRoute(route{m.method, "/"}); err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+		weights = append(weights, m.weight)
+	}
+	return methods, checkWeights(weights)
+}
+
+func parseProbability(s string) (float64, error) {
+	p, err := strconv.ParseFloat(s, 64)
+	if err == nil && (p < 0 || p > 1) {
+		err = fmt.Errorf("not within 0 and 1")
+	}
+	return p, err
+}
+
+func checkWeights(weights []float64) error {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return fmt.Errorf("all weights are 0")
+	}
+	return nil
+}
+
+// pick returns an index of weights, drawn by its weight.
+func pick(rnd *rand.Rand, weights []float64) int {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	x := rnd.Float64() * sum
+	for i, w := range weights {
+		if x < w {
+			return i
+		}
+		x -= w
+	}
+	return len(weights) - 1
+}
+
+// synthNode is a node of the prefix tree of a synthetic route set. A
+// parameter child is named after its depth, :p1 to :pN, so a path does not
+// repeat parameter names. Its values in the requests are numbers, which keeps
+// them from matching the words of its static siblings.
+type synthNode struct {
+	children []string
+}
+
+// synthRoutes generates the routes of shape sh and their requests. The same
+// shape always gives the same routes, in the order they were generated.
+func synthRoutes(sh synthShape) ([]route, []request, error) {
+	rnd := rand.New(rand.NewSource(sh.seed))
+	word := genString(lower, 3, 10)
+	nodes := make(map[string]*synthNode)
+	node := func(prefix string, depth int) *synthNode {
+		if n, ok := nodes[prefix]; ok {
+			return n
+		}
+		n := new(synthNode)
+		statics := sh.fanOut
+		if rnd.Float64() < sh.params {
+			n.children = append(n.children, ":p"+strconv.Itoa(depth+1))
+			statics = 0
+			if rnd.Float64() < sh.conflicts {
+				statics = sh.fanOut - 1
+			}
+		}
+		seen := make(map[string]bool)
+		for len(seen) < statics {
+			w := word(rnd)
+			if !seen[w] {
+				seen[w] = true
+				n.children = append(n.children, w)
+			}
+		}
+		nodes[prefix] = n
+		return n
+	}
+
+	methods := make([]float64, len(sh.methods))
+	for i, m := range sh.methods {
+		methods[i] = m.weight
+	}
+	routes := make([]route, 0, sh.routes)
+	seen := make(map[route]bool)
+	// a shape with few paths leaves generating to fail over and over
+	for failed := 0; len(routes) < sh.routes; {
+		depth := pick(rnd, sh.depths) + 1
+		path := ""
+		for d := 0; d < depth; d++ {
+			n := node(path, d)
+			path += "/" + n.children[rnd.Intn(len(n.children))]
+		}
+		r := route{sh.methods[pick(rnd, methods)].method, path}
+		if seen[r] {
+			if failed++; failed > 100*sh.routes+1000 {
+				return nil, nil, fmt.Errorf("the shape has room for fewer than %d routes", sh.routes)
+			}
+			continue
+		}
+		seen[r] = true
+		routes = append(routes, r)
+	}
+	requests := genRequests(routes, sh.seed)
+	number := genNumber(1, 99999999)
+	for i := range requests {
+		req := &requests[i]
+		for _, seg := range routes[i].segments() {
+			if seg.kind == paramSegment {
+				req.params[seg.name] = number(rnd)
+			}
+		}
+		req.path = requestPath(routes[i], req.params)
+	}
+	return routes, requests, nil
+}
+
+// synthFlag is the flag of the shapes of synthetic suites, which may be given
+// more than once.
+type synthFlag []string
+
+func (f *synthFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *synthFlag) Set(spec string) error {
+	*f = append(*f, spec)
+	return nil
+}
+
+// registerSynth registers a suite for each of the shape specs.
+func registerSynth(specs []string) error {
+	for _, spec := range specs {
+		sh, err := parseSynthShape(spec)
+		if err != nil {
+			return fmt.Errorf("synthetic suite %q: %v", spec, err)
+		}
+		routes, requests, err := synthRoutes(sh)
+		if err != nil {
+			return fmt.Errorf("synthetic suite %q: %v", spec, err)
+		}
+		s, err := newRouteSuite(sh.name, routes, requests)
+		if err != nil {
+			return fmt.Errorf("synthetic suite %q: %v", spec, err)
+		}
+		registerSuite(s)
+	}
+	return nil
+}
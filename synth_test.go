@@ -0,0 +1,139 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSynthShape(t *testing.T) {
+	sh, err := parseSynthShape("routes=5000,params=0.6,fanout=20,depth=2-3,methods=GET:3/POST,conflicts=0.1,seed=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := synthShape{
+		name:      "Synth5000",
+		routes:    5000,
+		depths:    []float64{0, 1, 1},
+		params:    0.6,
+		conflicts: 0.1,
+		fanOut:    20,
+		methods:   []methodWeight{{"GET", 3}, {"POST", 1}},
+		seed:      7,
+	}
+	if !reflect.DeepEqual(sh, want) {
+		t.Errorf("shape %+v, want %+v", sh, want)
+	}
+
+	sh, err = parseSynthShape("name=Deep,depth=1:1/4:3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh.name != "Deep" || !reflect.DeepEqual(sh.depths, []float64{1, 0, 0, 3}) {
+		t.Errorf("shape %+v", sh)
+	}
+
+	for _, spec := range []string{
+		"routes", "routes=0", "size=10", "depth=0", "depth=3-2", "depth=1:0",
+		"params=1.5", "fanout=0", "methods=get", "methods=PURGE", "methods=GET:-1", "seed=x",
+	} {
+		if _, err := parseSynthShape(spec); err == nil {
+			t.Errorf("no error for %q", spec)
+		}
+	}
+}
+
+func TestSynthRoutes(t *testing.T) {
+	sh, _ := parseSynthShape("routes=2000,params=0.5,fanout=8,depth=2-4")
+	routes, requests, err := synthRoutes(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, againRequests, _ := synthRoutes(sh)
+	if !reflect.DeepEqual(routes, again) || !reflect.DeepEqual(requests, againRequests) {
+		t.Error("the same shape gave other routes")
+	}
+	if len(routes) != 2000 || len(requests) != 2000 {
+		t.Fatalf("%d routes and %d requests, want 2000", len(routes), len(requests))
+	}
+
+	seen := make(map[string]bool)
+	methods := make(map[string]int)
+	var segments, params int
+	for _, r := range routes {
+		if err := checkRoute(r); err != nil {
+			t.Fatal(err)
+		}
+		if seen[r.key()] {
+			t.Fatalf("%s %s generated twice", r.method, r.path)
+		}
+		seen[r.key()] = true
+		methods[r.method]++
+		segs := r.segments()
+		if len(segs) < 2 || len(segs) > 4 {
+			t.Errorf("%s has depth %d", r.path, len(segs))
+		}
+		for _, seg := range segs {
+			segments++
+			if seg.kind == paramSegment {
+				params++
+			}
+		}
+	}
+	if p := float64(params) / float64(segments); p < 0.05 || p > 0.5 {
+		t.Errorf("%.2f of the segments are parameters", p)
+	}
+	// a request whose parameter value is a static sibling routes to it
+	for _, req := range requests {
+		for name, value := range req.params {
+			if strings.Trim(value, lower) == "" {
+				t.Fatalf("%s: parameter %s has the value %q of a static segment", req.path, name, value)
+			}
+		}
+	}
+	if methods["GET"] < methods["POST"] || methods["POST"] == 0 || methods["DELETE"] == 0 {
+		t.Errorf("method mix %v", methods)
+	}
+	for _, needs := range routeNeeds(routes) {
+		if needs&CapConflict != 0 {
+			t.Fatal("conflicts without conflicts in the shape")
+		}
+	}
+
+	sh.conflicts = 0.5
+	routes, _, _ = synthRoutes(sh)
+	conflicts := 0
+	for _, needs := range routeNeeds(routes) {
+		if needs&CapConflict != 0 {
+			conflicts++
+		}
+	}
+	if conflicts == 0 {
+		t.Error("no conflicts")
+	}
+}
+
+func TestSynthRoutesMethods(t *testing.T) {
+	sh, _ := parseSynthShape("routes=50,methods=GET/OPTIONS/HEAD/TRACE")
+	routes, requests, err := synthRoutes(sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLoadRouters(t, routes, requests)
+}
+
+func TestSynthRoutesRoom(t *testing.T) {
+	// 2 paths of depth 1 with 2 methods
+	sh, _ := parseSynthShape("routes=5,fanout=2,depth=1,params=0,methods=GET/POST")
+	if _, _, err := synthRoutes(sh); err == nil {
+		t.Error("generated 5 routes in room for 4")
+	}
+	sh.routes = 4
+	if routes, _, err := synthRoutes(sh); err != nil || len(routes) != 4 {
+		t.Errorf("%d routes, %v", len(routes), err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// verify loads routes into r with a distinct handler per route and sends the
+// matching one of requests for every route. It returns an error listing each
+// request that was not served by the handler registered for its method and
+// pattern, or whose path parameters did not reach the handler. It fails for
+// routes the dialect of r can not express.
+func verify(r Router, routes []route, requests []request) error {
+	served := -1
+	var got map[string]string
+
+	mux := r.New()
+	d := r.Dialect()
+	for i, route := range routes {
+		path, err := d.pattern(route.path)
+		if err != nil {
+			return fmt.Errorf("%s %s: %v", route.method, route.path, err)
+		}
+		i, segs := i, route.segments()
+		mux.Handle(route.method, path, func(ps Params) string {
+			served = i
+			for _, seg := range segs {
+				switch seg.kind {
+				case paramSegment:
+					got[seg.name] = ps.Get(d.paramKey(seg))
+				case catchAllSegment:
+					// some routers include the slash before the catch-all
+					got[seg.name] = strings.TrimPrefix(ps.Get(d.paramKey(seg)), "/")
+				}
+			}
+			return ""
+		})
+	}
+	router := mux.Build()
+
+	var failed []string
+	for i, rq := range requests {
+		route, path := routes[i], rq.path
+		served, got = -1, make(map[string]string)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(rq.method, path, nil)
+		router.ServeHTTP(w, req)
+
+		switch {
+		case served < 0:
+			failed = append(failed, fmt.Sprintf("%s %s: no handler ran, status %d",
+				route.method, path, w.Code))
+		case served != i:
+			failed = append(failed, fmt.Sprintf("%s %s: served by %s %s instead of %s",
+				route.method, path, routes[served].method, routes[served].path, route.path))
+		default:
+			for name, value := range rq.params {
+				if got[name] != value {
+					failed = append(failed, fmt.Sprintf("%s %s: param %s is %q instead of %q",
+						route.method, path, name, got[name], value))
+				}
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return &verifyError{failed, len(routes)}
+	}
+	return nil
+}
+
+// verifyError is returned by verify. It lists the failed requests.
+type verifyError struct {
+	failed []string
+	routes int
+}
+
+func (e *verifyError) Error() string {
+	return fmt.Sprintf("%d failures in %d routes:\n\t%s",
+		len(e.failed), e.routes, strings.Join(e.failed, "\n\t"))
+}
@@ -0,0 +1,27 @@
+// Copyright 2014 Julien Schmidt. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	if err := verify(serveMuxRouter{}, staticSuite.routes, staticSuite.requests); err != nil {
+		t.Errorf("HttpServeMux on static routes: %v", err)
+	}
+	if err := verify(gojiRouter{}, gplusSuite.routes, gplusSuite.requests); err != nil {
+		t.Errorf("Goji on GPlusAPI: %v", err)
+	}
+
+	// http.ServeMux registers parameterized patterns as literal paths
+	routes := []route{
+		{"GET", "/1/users"},
+		{"GET", "/1/users/:objectId"},
+	}
+	if err := verify(serveMuxRouter{}, routes, genRequests(routes, requestSeed)); err == nil {
+		t.Error("HttpServeMux on the /1/users routes of ParseAPI: misrouting not detected")
+	}
+}